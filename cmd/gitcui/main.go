@@ -26,6 +26,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
@@ -42,25 +43,99 @@ type RunOptions struct {
 	URL string
 	Path string
 	DoClone bool
+	PageSize int
+	Rev string
+	Columns []string
+	RelativeDate bool
+	DiffContext int
+	RenameThreshold int
+	IgnoreAllSpace bool
+	IgnoreSpaceChange bool
+	IgnoreBlankLines bool
+	Author string
+	Since string
+	Until string
+	Bookmarks string
 }
 
 func (o *RunOptions) addFlags(cmd *cobra.Command, flags *pflag.FlagSet) {
 	flags.BoolVar(&o.DoClone, "clone", false, "If specified, clone from the given url")
+	flags.IntVar(&o.PageSize, "page-size", ui.DefaultPageSize, "Number of commits to load at a time")
+	flags.StringVar(&o.Rev, "rev", "", "Branch, tag, remote branch, hash or range(A..B) to show the history of. HEAD if not specified")
+	flags.StringSliceVar(&o.Columns, "columns", nil, "Columns of the commit list, among graph, hash, refs, message, author and date. All if not specified")
+	flags.BoolVar(&o.RelativeDate, "relative-date", false, "If specified, show commit dates relative to now")
+	flags.IntVarP(&o.DiffContext, "unified", "U", ui.HunkContextLines, "Number of unchanged lines shown around changes in diffs")
+	flags.IntVarP(&o.RenameThreshold, "find-renames", "M", ui.DefaultRenameThreshold, "Similarity in percent from which files are detected as renamed or copied. 0 disables the detection")
+	flags.BoolVarP(&o.IgnoreAllSpace, "ignore-all-space", "w", false, "If specified, ignore whitespace when comparing lines")
+	flags.BoolVarP(&o.IgnoreSpaceChange, "ignore-space-change", "b", false, "If specified, ignore changes in the amount of whitespace")
+	flags.BoolVar(&o.IgnoreBlankLines, "ignore-blank-lines", false, "If specified, ignore changes whose lines are all blank")
+	flags.StringVar(&o.Author, "author", "", "Only show commits whose author(name <email>) matches the regular expression")
+	flags.StringVar(&o.Since, "since", "", "Only show commits committed since the date, e.g. 2006-01-02 or \"2 weeks ago\"")
+	flags.StringVar(&o.Until, "until", "", "Only show commits committed until the date, e.g. 2006-01-02 or \"2 weeks ago\"")
+	flags.StringVar(&o.Bookmarks, "bookmarks", ui.DefaultBookmarksPath(), "File bookmarks of all repositories are stored in")
+}
+
+// whitespaceMode returns the whitespace differences ignored by diffs
+func (o *RunOptions) whitespaceMode() ui.WhitespaceMode {
+	var mode ui.WhitespaceMode
+	if o.IgnoreAllSpace {
+		mode |= ui.IgnoreAllSpace
+	}
+	if o.IgnoreSpaceChange {
+		mode |= ui.IgnoreSpaceChange
+	}
+	if o.IgnoreBlankLines {
+		mode |= ui.IgnoreBlankLines
+	}
+	return mode
 }
 
 func main() {
 	runOptions := RunOptions{}
 
 	rootCmd := &cobra.Command{
-		Use: "gitcui <url or path>",
+		Use: "gitcui <url or path> [-- <path>...]",
 		Long: "CLI to play with git library",
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 
+			filter, err := ui.ParseLogFilter(runOptions.Author, runOptions.Since, runOptions.Until)
+			if err != nil {
+				log.Printf("Invalid filter: %v\n", err)
+				os.Exit(1)
+			}
+
+			// paths after -- limit the history
+			var paths []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				if dash == 0 {
+					log.Printf("A url or path of the repository is required before --\n")
+					os.Exit(1)
+				}
+				paths = args[dash:]
+			}
+
 			path := args[0]
 
+			if runOptions.DiffContext < 0 {
+				log.Printf("Invalid number of context lines: %d\n", runOptions.DiffContext)
+				os.Exit(1)
+			}
+
+			if runOptions.RenameThreshold < 0 || runOptions.RenameThreshold > 100 {
+				log.Printf("Invalid rename similarity, expected 0 to 100: %d\n", runOptions.RenameThreshold)
+				os.Exit(1)
+			}
+
+			columns, err := ui.ParseCommitColumns(runOptions.Columns)
+			if err != nil {
+				log.Printf("Invalid columns: %v\n", err)
+				os.Exit(1)
+			}
+
 			var repo *git.Repository
-			var err error
+			// bookmarks are stored by the url or the absolute path of the repository
+			repoKey := path
 
 			if runOptions.DoClone {
 				log.Printf("Clone %s\n", path)
@@ -83,9 +158,25 @@ func main() {
 					log.Printf("Failed to open: %v\n", err)
 					os.Exit(1)
 				}
+
+				if abs, err := filepath.Abs(path); err == nil {
+					repoKey = abs
+				}
 			}
 
-			ui.Run(repo)
+			ui.Run(repo, ui.Options{
+				PageSize: runOptions.PageSize,
+				Rev: runOptions.Rev,
+				Paths: paths,
+				Filter: filter,
+				Columns: columns,
+				RelativeDate: runOptions.RelativeDate,
+				DiffContext: runOptions.DiffContext,
+				RenameThreshold: runOptions.RenameThreshold,
+				Whitespace: runOptions.whitespaceMode(),
+				BookmarksPath: runOptions.Bookmarks,
+				RepoKey: repoKey,
+			})
 		},
 	}
 
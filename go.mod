@@ -1,6 +1,10 @@
 module github.com/jparklab/gitcui
 
+go 1.13
+
 require (
+	github.com/alecthomas/chroma v0.10.0
+	github.com/dlclark/regexp2 v1.4.0 // indirect
 	github.com/fatih/color v1.7.0
 	github.com/gdamore/tcell v1.1.1
 	github.com/jroimartin/gocui v0.4.0
@@ -8,6 +12,7 @@ require (
 	github.com/mattn/go-colorable v0.0.9 // indirect
 	github.com/mattn/go-isatty v0.0.4 // indirect
 	github.com/rivo/tview v0.0.0-20181225175557-e432b27b038f
+	github.com/sergi/go-diff v1.0.0
 	github.com/sirupsen/logrus v1.2.0
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/pflag v1.0.3
@@ -0,0 +1,134 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	// decoders of the image formats whose dimensions are shown
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// MaxBinarySniffSize is the number of bytes read from the start of a binary file
+// to detect its type and dimensions
+const MaxBinarySniffSize = 64 * 1024
+
+// binaryDiff describes a change of a binary file
+type binaryDiff struct {
+	path string
+	// from and to are nil if the file is added or deleted
+	from *binaryBlob
+	to *binaryBlob
+}
+
+// binaryBlob describes the content of a binary file
+type binaryBlob struct {
+	hash plumbing.Hash
+	size int64
+	mime string
+	// width and height are 0 unless the file is an image
+	width int
+	height int
+}
+
+// newBinaryDiff inspects both sides of the change of a binary file
+func newBinaryDiff(ctx context.Context, change *object.Change) (*binaryDiff, error) {
+	d := &binaryDiff{path: changePath(change)}
+
+	var err error
+	if d.from, err = newBinaryBlob(ctx, change.From); err != nil {
+		return nil, err
+	}
+	if d.to, err = newBinaryBlob(ctx, change.To); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// newBinaryBlob inspects the file of entry, or returns nil if there is none
+func newBinaryBlob(ctx context.Context, entry object.ChangeEntry) (*binaryBlob, error) {
+	if entry.Name == "" {
+		return nil, nil
+	}
+
+	file, err := entry.Tree.TreeEntryFile(&entry.TreeEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	head, err := ioutil.ReadAll(io.LimitReader(reader, MaxBinarySniffSize))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &binaryBlob{
+		hash: file.Hash,
+		size: file.Size,
+		mime: http.DetectContentType(head),
+	}
+	if config, _, err := image.DecodeConfig(bytes.NewReader(head)); err == nil {
+		b.width, b.height = config.Width, config.Height
+	}
+
+	return b, nil
+}
+
+// formatSize formats a number of bytes
+func formatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d bytes", size)
+	}
+
+	value, unit := float64(size) / 1024, "KiB"
+	for _, u := range []string{"MiB", "GiB"} {
+		if value < 1024 {
+			break
+		}
+		value, unit = value / 1024, u
+	}
+	return fmt.Sprintf("%.1f %s (%d bytes)", value, unit, size)
+}
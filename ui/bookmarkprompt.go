@@ -0,0 +1,106 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// BookmarkPromptTitle is the title of the bookmark prompt unless saving failed
+const BookmarkPromptTitle = "Bookmark the commit"
+
+// BookmarkPromptView is a popup to enter the name of a bookmark
+type BookmarkPromptView interface {
+	GetView() *tview.InputField
+
+	// Open clears the prompt to bookmark commit
+	Open(commit *object.Commit)
+}
+
+type bookmarkPromptView struct {
+	top TopLevelView
+	bookmarks BookmarkStore
+
+	view *tview.InputField
+	commit *object.Commit
+}
+
+////////////////////////////////////////////////////////////
+// bookmarkPromptView functions
+////////////////////////////////////////////////////////////
+
+// NewBookmarkPromptView creates an instance of BookmarkPromptView
+func NewBookmarkPromptView(top TopLevelView, bookmarks BookmarkStore) BookmarkPromptView {
+	inputField := tview.NewInputField().
+		SetLabel("Name: ")
+
+	inputField.
+		SetBorder(true).
+		SetTitle(BookmarkPromptTitle)
+
+	bv := &bookmarkPromptView{
+		top: top,
+		bookmarks: bookmarks,
+		view: inputField,
+	}
+
+	inputField.SetDoneFunc(bv.done)
+
+	return bv
+}
+
+func (bv *bookmarkPromptView) GetView() *tview.InputField {
+	return bv.view
+}
+
+func (bv *bookmarkPromptView) Open(commit *object.Commit) {
+	bv.commit = commit
+	bv.view.SetText("")
+	bv.view.SetTitle(BookmarkPromptTitle + " " + shortHash(commit))
+}
+
+func (bv *bookmarkPromptView) done(key tcell.Key) {
+	if key != tcell.KeyEnter {
+		bv.top.ClosePopup()
+		return
+	}
+
+	name := bv.view.GetText()
+	if name == "" {
+		return
+	}
+
+	if err := bv.bookmarks.Add(name, bv.commit.Hash); err != nil {
+		// keep the prompt open to retry
+		bv.view.SetTitle(tview.Escape("Failed to save: " + err.Error()))
+		return
+	}
+
+	bv.top.ClosePopup()
+	bv.top.NotifyBookmarksChange()
+}
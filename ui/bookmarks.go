@@ -0,0 +1,194 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// Bookmark is a named commit
+type Bookmark struct {
+	Name string
+	Hash plumbing.Hash
+}
+
+// BookmarkStore keeps the bookmarks of a repository in a file
+// shared by all repositories
+type BookmarkStore interface {
+	// List returns the bookmarks sorted by name
+	List() []Bookmark
+
+	// Add adds a bookmark, replacing the one with the same name
+	Add(name string, hash plumbing.Hash) error
+
+	// Remove removes the bookmark with the name
+	Remove(name string) error
+}
+
+type bookmarkStore struct {
+	path string
+	repoKey string
+
+	bookmarks []Bookmark
+}
+
+// bookmarkFile is the content of the file, bookmarks by repository
+type bookmarkFile map[string][]bookmarkRecord
+
+// bookmarkRecord is a bookmark as stored in the file
+type bookmarkRecord struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// DefaultBookmarksPath returns the file bookmarks are stored in by default
+func DefaultBookmarksPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gitcui", "bookmarks.json")
+}
+
+////////////////////////////////////////////////////////////
+// bookmarkStore functions
+////////////////////////////////////////////////////////////
+
+// NewBookmarkStore creates an instance of BookmarkStore
+// for the repository identified by repoKey, loading its bookmarks from path.
+// Bookmarks are kept in memory only if path is empty
+func NewBookmarkStore(path, repoKey string) (BookmarkStore, error) {
+	s := &bookmarkStore{
+		path: path,
+		repoKey: repoKey,
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	f, err := s.read()
+	if err != nil {
+		return s, err
+	}
+	for _, r := range f[repoKey] {
+		s.bookmarks = append(s.bookmarks, Bookmark{Name: r.Name, Hash: plumbing.NewHash(r.Hash)})
+	}
+
+	return s, nil
+}
+
+func (s *bookmarkStore) List() []Bookmark {
+	bookmarks := make([]Bookmark, len(s.bookmarks))
+	copy(bookmarks, s.bookmarks)
+	sort.Slice(bookmarks, func(i, j int) bool {
+		return bookmarks[i].Name < bookmarks[j].Name
+	})
+
+	return bookmarks
+}
+
+func (s *bookmarkStore) Add(name string, hash plumbing.Hash) error {
+	var bookmarks []Bookmark
+	for _, b := range s.bookmarks {
+		if b.Name != name {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	s.bookmarks = append(bookmarks, Bookmark{Name: name, Hash: hash})
+
+	return s.save()
+}
+
+func (s *bookmarkStore) Remove(name string) error {
+	var bookmarks []Bookmark
+	for _, b := range s.bookmarks {
+		if b.Name != name {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	s.bookmarks = bookmarks
+
+	return s.save()
+}
+
+// read reads the bookmarks of all repositories
+func (s *bookmarkStore) read() (bookmarkFile, error) {
+	f := make(bookmarkFile)
+
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return f, nil
+	} else if err != nil {
+		return f, err
+	}
+
+	err = json.Unmarshal(data, &f)
+	return f, err
+}
+
+// save writes the bookmarks, keeping the ones of other repositories
+func (s *bookmarkStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if len(s.bookmarks) > 0 {
+		var records []bookmarkRecord
+		for _, b := range s.bookmarks {
+			records = append(records, bookmarkRecord{Name: b.Name, Hash: b.Hash.String()})
+		}
+		f[s.repoKey] = records
+	} else {
+		delete(f, s.repoKey)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	// replace the file at once not to leave it half written
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,186 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+// BookmarkListTitle is the title of the bookmark list unless removing failed
+const BookmarkListTitle = "Bookmarks (Enter to go, d to delete)"
+
+// BookmarkListView is a popup to choose the bookmark to go to
+type BookmarkListView interface {
+	GetView() *tview.List
+
+	// Reload refreshes the list of bookmarks
+	Reload()
+}
+
+type bookmarkListView struct {
+	top TopLevelView
+	worker Worker
+	repo *git.Repository
+	bookmarks BookmarkStore
+
+	view *tview.List
+	entries []Bookmark
+}
+
+////////////////////////////////////////////////////////////
+// bookmarkListView functions
+////////////////////////////////////////////////////////////
+
+// NewBookmarkListView creates an instance of BookmarkListView
+func NewBookmarkListView(top TopLevelView, worker Worker, repo *git.Repository, bookmarks BookmarkStore) BookmarkListView {
+	listView := tview.NewList().
+		ShowSecondaryText(false)
+
+	listView.
+		SetBorder(true).
+		SetTitle(BookmarkListTitle)
+
+	bv := &bookmarkListView{
+		top: top,
+		worker: worker,
+		repo: repo,
+		bookmarks: bookmarks,
+		view: listView,
+	}
+
+	listView.SetSelectedFunc(bv.bookmarkSelected)
+	listView.SetDoneFunc(func() {
+		// the bookmark chosen is no longer gone to
+		worker.Cancel(JobGoto)
+		top.ClosePopup()
+	})
+	listView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 'd' {
+			bv.removeSelected()
+			return nil
+		}
+		return event
+	})
+
+	return bv
+}
+
+func (bv *bookmarkListView) GetView() *tview.List {
+	return bv.view
+}
+
+func (bv *bookmarkListView) Reload() {
+	bv.reload(0, BookmarkListTitle)
+}
+
+// reload lists the bookmarks with the subjects of their commits read in the background,
+// selecting the one at current, under the title
+func (bv *bookmarkListView) reload(current int, title string) {
+	entries := bv.bookmarks.List()
+	repo := bv.repo
+	bv.worker.Submit(JobBookmarks, func(ctx context.Context) func() {
+		subjects := make([]string, len(entries))
+		for idx, b := range entries {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if commit, err := repo.CommitObject(b.Hash); err == nil {
+				subjects[idx] = commitSubject(commit)
+			}
+		}
+
+		return func() {
+			bv.setEntries(entries, subjects, current, title)
+		}
+	})
+}
+
+func (bv *bookmarkListView) setEntries(entries []Bookmark, subjects []string, current int, title string) {
+	bv.entries = entries
+	bv.view.Clear()
+	bv.view.SetTitle(title)
+
+	for idx, b := range entries {
+		text := fmt.Sprintf("%-20s %s", b.Name, b.Hash.String()[:ShortHashLength])
+		if subjects[idx] != "" {
+			text += " " + subjects[idx]
+		}
+		bv.view.AddItem(tview.Escape(text), "", 0, nil)
+	}
+
+	if current >= len(entries) {
+		current = len(entries) - 1
+	}
+	if current >= 0 {
+		bv.view.SetCurrentItem(current)
+	}
+}
+
+func (bv *bookmarkListView) bookmarkSelected(index int, mainText, secondaryText string, shortcut rune) {
+	if index < 0 || index >= len(bv.entries) {
+		return
+	}
+
+	repo := bv.repo
+	hash := bv.entries[index].Hash
+	bv.worker.Submit(JobGoto, func(ctx context.Context) func() {
+		commit, err := repo.CommitObject(hash)
+		return func() {
+			if err != nil {
+				// the commit may have been garbage collected
+				bv.view.SetTitle(tview.Escape(err.Error()))
+				return
+			}
+
+			bv.top.ClosePopup()
+			bv.top.SelectCommit(commit)
+		}
+	})
+}
+
+func (bv *bookmarkListView) removeSelected() {
+	index := bv.view.GetCurrentItem()
+	if index < 0 || index >= len(bv.entries) {
+		return
+	}
+
+	// the bookmark is gone from the list even if it could not be saved
+	title := BookmarkListTitle
+	if err := bv.bookmarks.Remove(bv.entries[index].Name); err != nil {
+		log.Printf("Failed to remove bookmark: %v\n", err)
+		title = fmt.Sprintf("Bookmarks (failed to save: %s)", tview.Escape(err.Error()))
+	}
+
+	bv.reload(index, title)
+	bv.top.NotifyBookmarksChange()
+}
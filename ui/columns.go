@@ -0,0 +1,213 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// CommitColumn is a column of the commit list
+type CommitColumn string
+
+const (
+	// ColumnGraph shows the commit graph
+	ColumnGraph CommitColumn = "graph"
+	// ColumnHash shows the abbreviated hash
+	ColumnHash CommitColumn = "hash"
+	// ColumnRefs shows branches and tags pointing to the commit
+	ColumnRefs CommitColumn = "refs"
+	// ColumnMessage shows the subject of the commit message
+	ColumnMessage CommitColumn = "message"
+	// ColumnAuthor shows the author name
+	ColumnAuthor CommitColumn = "author"
+	// ColumnDate shows the author date
+	ColumnDate CommitColumn = "date"
+
+	// columnMarks is the gutter showing marks and bookmarks,
+	// which comes before the configured columns
+	columnMarks CommitColumn = ""
+)
+
+// DefaultCommitColumns are the columns shown if not configured
+var DefaultCommitColumns = []CommitColumn{
+	ColumnGraph,
+	ColumnHash,
+	ColumnDate,
+	ColumnAuthor,
+	ColumnRefs,
+	ColumnMessage,
+}
+
+// sortableColumns are the columns the list can be sorted by, in the order
+// they are cycled through
+var sortableColumns = []CommitColumn{
+	ColumnDate,
+	ColumnAuthor,
+	ColumnMessage,
+	ColumnHash,
+}
+
+// AbsoluteDateFormat is the format of dates unless relative dates are shown
+const AbsoluteDateFormat = "2006-01-02 15:04"
+
+// Colors of ref decorations
+const (
+	DecorationColorHead = tcell.ColorAqua
+	DecorationColorBranch = tcell.ColorGreen
+	DecorationColorRemote = tcell.ColorRed
+	DecorationColorTag = tcell.ColorYellow
+)
+
+// Colors of the marks and bookmarks tagging commits
+const (
+	MarkColor = tcell.ColorBlack
+	MarkBackgroundColor = tcell.ColorFuchsia
+)
+
+// ParseCommitColumns converts column names to columns
+func ParseCommitColumns(names []string) ([]CommitColumn, error) {
+	var columns []CommitColumn
+	for _, name := range names {
+		column := CommitColumn(strings.TrimSpace(name))
+		switch column {
+		case ColumnGraph, ColumnHash, ColumnRefs, ColumnMessage, ColumnAuthor, ColumnDate:
+			columns = append(columns, column)
+		default:
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+
+	return columns, nil
+}
+
+// ShortHashLength is the number of hex digits shown of commit hashes
+const ShortHashLength = 10
+
+// shortHash returns the abbreviated hash of the commit
+func shortHash(commit *object.Commit) string {
+	return commit.Hash.String()[:ShortHashLength]
+}
+
+// commitSubject returns the first line of the commit message
+func commitSubject(commit *object.Commit) string {
+	return messageSubject(commit.Message)
+}
+
+// messageSubject returns the first line of a commit or tag message
+func messageSubject(message string) string {
+	return strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+}
+
+// formatDate formats t either as an absolute date or relative to now
+func formatDate(t time.Time, relative bool, now time.Time) string {
+	if !relative {
+		return t.Local().Format(AbsoluteDateFormat)
+	}
+
+	d := now.Sub(t)
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d / time.Minute), "minute")
+	case d < 24 * time.Hour:
+		return plural(int(d / time.Hour), "hour")
+	case d < 30 * 24 * time.Hour:
+		return plural(int(d / (24 * time.Hour)), "day")
+	case d < 365 * 24 * time.Hour:
+		return plural(int(d / (30 * 24 * time.Hour)), "month")
+	}
+	return plural(int(d / (365 * 24 * time.Hour)), "year")
+}
+
+// formatDecorations renders refs pointing to a commit with color tags
+func formatDecorations(refs []refEntry) string {
+	var names []string
+	for _, r := range refs {
+		var color tcell.Color
+		name := r.name
+		switch r.kind {
+		case RefKindHead:
+			color = DecorationColorHead
+		case RefKindBranch:
+			color = DecorationColorBranch
+		case RefKindRemote:
+			color = DecorationColorRemote
+		case RefKindTag:
+			color = DecorationColorTag
+			name = "tag: " + name
+		}
+
+		names = append(names, fmt.Sprintf("[#%06x]%s[-]", color.Hex(), tview.Escape(name)))
+	}
+
+	return strings.Join(names, " ")
+}
+
+// formatMarks renders the marks tagging a commit with color tags
+func formatMarks(marks []string) string {
+	var texts []string
+	for _, m := range marks {
+		texts = append(texts, fmt.Sprintf("[#%06x:#%06x]%s[-:-]",
+			MarkColor.Hex(), MarkBackgroundColor.Hex(), tview.Escape(m)))
+	}
+	return strings.Join(texts, " ")
+}
+
+// compareCommits compares commits by the column, returning a negative number
+// if a comes first in ascending order
+func compareCommits(column CommitColumn, a, b *object.Commit) int {
+	switch column {
+	case ColumnDate:
+		switch {
+		case a.Author.When.Before(b.Author.When):
+			return -1
+		case a.Author.When.After(b.Author.When):
+			return 1
+		}
+		return 0
+	case ColumnAuthor:
+		return strings.Compare(strings.ToLower(a.Author.Name), strings.ToLower(b.Author.Name))
+	case ColumnMessage:
+		return strings.Compare(commitSubject(a), commitSubject(b))
+	case ColumnHash:
+		return strings.Compare(a.Hash.String(), b.Hash.String())
+	}
+	return 0
+}
@@ -0,0 +1,199 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// DefaultPageSize is the number of commits loaded at a time
+const DefaultPageSize int = 100
+
+// ScanBudget is how long a page is looked for, after which
+// the commits found so far are returned, e.g. when few commits pass the filter
+const ScanBudget = 200 * time.Millisecond
+
+// CommitLoader loads commits from a commit iterator one page at a time.
+// It is not safe to call LoadMore concurrently
+type CommitLoader interface {
+	// LoadMore returns the next page of commits, or the commits found
+	// within ScanBudget if fewer. When ctx is done, it stops early
+	// and keeps what it has read for the next call.
+	// Any other error ends the loading
+	LoadMore(ctx context.Context) ([]*object.Commit, error)
+
+	// Flush returns the commits kept by a cancelled LoadMore without reading more
+	Flush() []*object.Commit
+
+	// Scanned returns the number of commits read so far, whether loaded or filtered out
+	Scanned() int
+
+	// Close releases the iterator. It is not safe to call it during LoadMore
+	Close()
+
+	// HasMore returns false once the iterator has been exhausted
+	HasMore() bool
+}
+
+// CommitFilter returns true if commit should be loaded, or io.EOF
+// when no later commit can be. It should stop early once ctx is done
+type CommitFilter func(ctx context.Context, commit *object.Commit) (bool, error)
+
+// ContextCommitIter is a commit iterator that can stop early
+// while looking for the next commit, e.g. walking a range
+type ContextCommitIter interface {
+	// NextContext returns the next commit like Next.
+	// Once ctx is done, it stops early and the next call carries on
+	NextContext(ctx context.Context) (*object.Commit, error)
+}
+
+type commitLoader struct {
+	iter     object.CommitIter
+	pageSize int
+	filter   CommitFilter
+	eof      bool
+	scanned  int
+
+	// accepted and unchecked are what a cancelled LoadMore has read,
+	// respectively accepted by the filter and not checked yet
+	accepted  []*object.Commit
+	unchecked *object.Commit
+}
+
+////////////////////////////////////////////////////////////
+// commitLoader functions
+////////////////////////////////////////////////////////////
+
+// NewCommitLoader creates an instance of CommitLoader
+func NewCommitLoader(iter object.CommitIter, pageSize int) CommitLoader {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return &commitLoader{
+		iter:     iter,
+		pageSize: pageSize,
+	}
+}
+
+// NewFilteredCommitLoader creates an instance of CommitLoader
+// loading only the commits accepted by filter
+func NewFilteredCommitLoader(iter object.CommitIter, pageSize int, filter CommitFilter) CommitLoader {
+	l := NewCommitLoader(iter, pageSize).(*commitLoader)
+	l.filter = filter
+
+	return l
+}
+
+func (l *commitLoader) LoadMore(ctx context.Context) ([]*object.Commit, error) {
+	commits := l.accepted
+	l.accepted = nil
+
+	deadline := time.Now().Add(ScanBudget)
+	for len(commits) < l.pageSize && !l.eof && time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			l.accepted = commits
+			return nil, err
+		}
+
+		commit := l.unchecked
+		l.unchecked = nil
+		if commit == nil {
+			var err error
+			commit, err = l.next(ctx)
+			if err != nil && ctx.Err() != nil {
+				l.accepted = commits
+				return nil, ctx.Err()
+			} else if err == io.EOF {
+				l.Close()
+				break
+			} else if err != nil {
+				return commits, l.fail(err)
+			}
+			l.scanned++
+		}
+
+		if l.filter != nil {
+			accepted, err := l.filter(ctx, commit)
+			if err != nil && ctx.Err() != nil {
+				// the commit is checked again by the next call
+				l.unchecked = commit
+				l.accepted = commits
+				return nil, ctx.Err()
+			} else if err == io.EOF {
+				l.Close()
+				break
+			} else if err != nil {
+				return commits, l.fail(err)
+			}
+			if !accepted {
+				continue
+			}
+		}
+
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// next reads the next commit, stopping early once ctx is done if the iterator can
+func (l *commitLoader) next(ctx context.Context) (*object.Commit, error) {
+	if iter, ok := l.iter.(ContextCommitIter); ok {
+		return iter.NextContext(ctx)
+	}
+	return l.iter.Next()
+}
+
+// fail closes the loader on an error, so that it is not read again on every page
+func (l *commitLoader) fail(err error) error {
+	l.Close()
+	return err
+}
+
+func (l *commitLoader) Flush() []*object.Commit {
+	commits := l.accepted
+	l.accepted = nil
+	return commits
+}
+
+func (l *commitLoader) Close() {
+	if !l.eof {
+		l.eof = true
+		l.iter.Close()
+	}
+}
+
+func (l *commitLoader) Scanned() int {
+	return l.scanned
+}
+
+func (l *commitLoader) HasMore() bool {
+	return !l.eof || len(l.accepted) > 0
+}
@@ -25,6 +25,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rivo/tview"
@@ -35,12 +36,14 @@ import (
 // CommitDetailView is a view to show details of a commit
 type CommitDetailView interface {
 	GetView() *tview.Table
-	SetSelected(commit *object.Commit)
+	// SetSelected shows the stats of the changes diff finds in the commit
+	SetSelected(commit *object.Commit, diff treeDiffFunc)
 }
 
 
 type commitDetailView struct {
 	top TopLevelView
+	worker Worker
 
 	columns []string
 	view *tview.Table
@@ -54,7 +57,7 @@ type commitDetailView struct {
 ////////////////////////////////////////////////////////////
 
 // NewCommitDetailView creates an instance of CommitDetailView
-func NewCommitDetailView(top TopLevelView) CommitDetailView {
+func NewCommitDetailView(top TopLevelView, worker Worker) CommitDetailView {
 	tableView :=  tview.NewTable().
 		SetBorders(false).
 		SetSelectable(
@@ -69,6 +72,7 @@ func NewCommitDetailView(top TopLevelView) CommitDetailView {
 
 	return &commitDetailView {
 		top: top,
+		worker: worker,
 		columns: []string{ "file", "added", "removed" },
 		view: tableView,
 	}
@@ -78,9 +82,40 @@ func (cv *commitDetailView) GetView() *tview.Table {
 	return cv.view
 }
 
-func (cv *commitDetailView) SetSelected(commit *object.Commit) {
-	stats, _ := commit.Stats()
+func (cv *commitDetailView) SetSelected(commit *object.Commit, diff treeDiffFunc) {
+	cv.worker.Submit(JobStats, func(ctx context.Context) func() {
+		stats, err := commitStats(ctx, commit, diff)
+		if err != nil {
+			return nil
+		}
+
+		return func() {
+			cv.setStats(commit, stats)
+		}
+	})
+}
+
+// commitStats computes the stats of changes diff finds in the commit
+func commitStats(ctx context.Context, commit *object.Commit, diff treeDiffFunc) (object.FileStats, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	_, changes, err := diff(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.Stats(), nil
+}
 
+func (cv *commitDetailView) setStats(commit *object.Commit, stats object.FileStats) {
 	// reset view
 	tableView := cv.view
 
@@ -26,8 +26,9 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
-	
+
 	"github.com/gdamore/tcell"
 	"github.com/rivo/tview"
 	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
@@ -35,24 +36,178 @@ import (
 
 // DiffView is a view that shows changes for a single text file
 type DiffView interface {
-	GetView() *tview.Table
+	GetView() *diffTable
+
+	// SetText shows the lines of a text file, or nothing if text is nil
+	SetText(text *textDiff)
+
+	// SetBinary shows what changed in a binary file
+	SetBinary(binary *binaryDiff)
 
-	SetFilePatch(patch diff.FilePatch)
+	// SetSplit sets whether old and new lines are shown side by side
+	SetSplit(split bool)
+
+	// lines of the patch can be searched
+	Searchable
 }
 
 type diffView struct {
 	top TopLevelView
-	view *tview.Table
+	view *diffTable
+
+	lines []diffLine
+	// binary is shown instead of lines if it is set
+	binary *binaryDiff
+	// highlighted is true if lines are colored by their syntax
+	highlighted bool
+	// renamed is "old → new" if the patch renames or copies a file
+	renamed string
+	// whitespace is what the patch ignores
+	whitespace WhitespaceMode
+	// split shows old and new lines side by side, and
+	// lineRows holds the row of the table each line is shown in
+	split bool
+	rows []diffRow
+	lineRows []int
+
+	// folds hide unchanged lines until they are expanded
+	folds []diffFold
+
+	search string
+	// matches are the occurrences of the search in lines,
+	// and curMatch is the index of the one moved to
+	matches []diffMatch
+	curMatch int
+}
+
+// textDiff is the lines of a text file patch ready to be shown
+type textDiff struct {
+	lines []diffLine
+	folds []diffFold
+	// highlighted is true if lines are colored by their syntax
+	highlighted bool
+	// renamed is "old → new" if the patch renames or copies a file
+	renamed string
+	// whitespace is what the patch ignores
+	whitespace WhitespaceMode
+}
+
+// diffTable is the table of the diff view, which tells when it is drawn with another width
+type diffTable struct {
+	*tview.Table
+	width int
+	resized func()
+}
+
+func (t *diffTable) Draw(screen tcell.Screen) {
+	// the width is only known once the table is laid out
+	if _, _, width, _ := t.GetInnerRect(); width != t.width {
+		t.width = width
+		t.resized()
+	}
+	t.Table.Draw(screen)
+}
+
+// diffLine is a line of a patch, or the header of a hunk
+type diffLine struct {
+	op diff.Operation
+	text string
+	// oldLine and newLine are the line numbers in the old and new file,
+	// 0 if the line is not part of it
+	oldLine int
+	newLine int
+	hunkHeader bool
+	// ignored is true for changed blank lines when they are ignored,
+	// which are only shown within the hunks of other changes
+	ignored bool
+	// changes are the ranges of words changed from the paired line
+	changes []textRange
+	// syntax colors the line, if the language of the file is known
+	syntax []syntaxSpan
+}
+
+// diffRow is a row of the table showing the lines at indices left and right,
+// or -1 for none. The unified mode only uses left, and the split mode
+// shows unchanged lines on both sides
+type diffRow struct {
+	left int
+	right int
+	// fold is the index of the fold shown in the row, or -1
+	fold int
+}
+
+// diffFold is a range of unchanged lines hidden behind a single row
+type diffFold struct {
+	// start and end are the indices of the first and past the last line
+	start int
+	end int
+	// ignored is the number of changed lines in the range, which are blank lines ignored
+	ignored int
+	expanded bool
+}
+
+// MinFoldLines is the number of unchanged lines from which they are folded
+const MinFoldLines = 2
+
+// diffMatch is an occurrence of the search in a line
+type diffMatch struct {
+	line int
+	// start and end are the byte offsets in the line
+	start int
+	end int
 }
 
 const (
 	LineColorInserted = tcell.ColorGreen
 	LineColorDeleted = tcell.ColorRed
+	LineColorHunkHeader = tcell.ColorAqua
+	LineNumberColor = tcell.ColorGray
+	FoldColor = tcell.ColorGray
+)
+
+// Background colors of changed lines colored by their syntax,
+// since their text color is taken by the syntax
+const (
+	LineBackgroundColorInserted = tcell.ColorDarkGreen
+	LineBackgroundColorDeleted = tcell.ColorMaroon
+)
+
+// Colors of the words changed within a line
+const (
+	WordColorChanged = tcell.ColorWhite
+	WordBackgroundColorInserted = tcell.ColorGreen
+	WordBackgroundColorDeleted = tcell.ColorRed
+)
+
+// Columns of the diff view
+const (
+	diffColumnOld = iota
+	diffColumnNew
+	diffColumnLine
+)
+
+// Columns of the diff view in the split mode
+const (
+	splitColumnOld = iota
+	splitColumnOldLine
+	splitColumnNew
+	splitColumnNewLine
 )
 
+// CurrentMatchBackgroundColor is the background color of the match moved to
+const CurrentMatchBackgroundColor = tcell.ColorFuchsia
+
 // ExpandTabStr is used to expand tabs into strings
 const ExpandTabStr = "    "
 
+// expandTabs replaces tabs with ExpandTabStr, since tview does not display tabs
+func expandTabs(s string) string {
+	return strings.Replace(s, "\t", ExpandTabStr, -1)
+}
+
+// DiffViewTitle is the title of the diff view
+const DiffViewTitle = "File Diff"
+
 ////////////////////////////////////////////////////////////
 // diffView methods
 ////////////////////////////////////////////////////////////
@@ -61,62 +216,718 @@ const ExpandTabStr = "    "
 func NewDiffView(top TopLevelView) DiffView {
 	tableView :=  tview.NewTable().
 		SetSelectable(
-			false,	// rows
+			true,	// rows
 			false,	// columns
 		)
 
 	tableView.
 		SetBorder(true).
-		SetTitle("File Diff")
+		SetTitle(DiffViewTitle)
 
-	return &diffView {
+	dv := &diffView {
 		top: top,
-		view: tableView,
 	}
+	// the sides of the split mode are as wide as half the table
+	dv.view = &diffTable{Table: tableView, resized: dv.tableResized}
+
+	// folds are expanded with Enter
+	tableView.SetSelectedFunc(dv.rowSelected)
+
+	return dv
 }
 
-func (tv *diffView) GetView() *tview.Table {
+func (tv *diffView) GetView() *diffTable {
 	return tv.view
 }
 
+// tableResized renders the split mode again for the width of the table
+func (tv *diffView) tableResized() {
+	if tv.split {
+		tv.render()
+	}
+}
+
+
+func (tv *diffView) SetText(text *textDiff) {
+	tv.binary = nil
+	if text == nil {
+		text = &textDiff{}
+	}
+	tv.lines = text.lines
+	tv.folds = text.folds
+	tv.highlighted = text.highlighted
+	tv.renamed = text.renamed
+	tv.whitespace = text.whitespace
+
+	tv.findMatches()
+	tv.layoutRows()
+	tv.render()
+	tv.view.Select(1, 0).ScrollToBeginning()
+	if len(tv.matches) > 0 {
+		tv.scrollToMatch()
+	}
+}
+
+// newTextDiff splits patch into lines colored by their syntax,
+// showing context unchanged lines around changes and folding the others
+func newTextDiff(patch diff.FilePatch, context int) *textDiff {
+	text := &textDiff{
+		renamed: patchRename(patch),
+		whitespace: patchWhitespaceMode(patch),
+	}
+
+	lines := patchLines(patch)
+	if lexer := fileLexer(patchPath(patch)); lexer != nil {
+		markSyntax(lines, lexer)
+		text.highlighted = true
+	}
+	text.lines, text.folds = foldLines(lines, context)
+
+	return text
+}
+
+// patchRename returns "old → new" if patch renames or copies a file,
+// or an empty string otherwise
+func patchRename(patch diff.FilePatch) string {
+	from, to := patch.Files()
+	if from == nil || to == nil || from.Path() == to.Path() {
+		return ""
+	}
+	return from.Path() + RenameArrow + to.Path()
+}
+
+// patchLines splits the chunks of patch into lines numbered in the old and new file
+func patchLines(patch diff.FilePatch) []diffLine {
+	var lines []diffLine
+	oldLine, newLine := 1, 1
+	ignoreBlankLines := patchWhitespaceMode(patch)&IgnoreBlankLines != 0
+	// deleted is the index of the first line of the previous chunk if it is a deletion
+	deleted := -1
+	for _, c := range patch.Chunks() {
+		if c.Content() == "" {
+			continue
+		}
+
+		start := len(lines)
+		for _, text := range strings.Split(strings.TrimSuffix(c.Content(), "\n"), "\n") {
+			l := diffLine{op: c.Type(), text: expandTabs(text)}
+			l.ignored = ignoreBlankLines && c.Type() != diff.Equal && isBlank(text)
+			switch c.Type() {
+			case diff.Equal:
+				l.oldLine, l.newLine = oldLine, newLine
+				oldLine++
+				newLine++
+			case diff.Delete:
+				l.oldLine = oldLine
+				oldLine++
+			case diff.Add:
+				l.newLine = newLine
+				newLine++
+			}
+			lines = append(lines, l)
+		}
+
+		if c.Type() == diff.Add && deleted >= 0 {
+			markWordChanges(lines[deleted:start], lines[start:])
+		}
+
+		deleted = -1
+		if c.Type() == diff.Delete {
+			deleted = start
+		}
+	}
+
+	return lines
+}
+
+// markWordChanges pairs deleted lines with the added lines replacing them,
+// and marks the words changed within each pair
+func markWordChanges(deleted, added []diffLine) {
+	for i := 0; i < len(deleted) && i < len(added); i++ {
+		deleted[i].changes, added[i].changes = wordDiff(deleted[i].text, added[i].text)
+	}
+}
+
+// foldLines inserts the @@ header line before each hunk of lines,
+// and folds the unchanged lines between hunks
+func foldLines(lines []diffLine, context int) ([]diffLine, []diffFold) {
+	var result []diffLine
+	var folds []diffFold
+
+	addUnchanged := func(unchanged []diffLine) {
+		if len(unchanged) >= MinFoldLines {
+			fold := diffFold{start: len(result), end: len(result) + len(unchanged)}
+			for _, l := range unchanged {
+				if l.ignored {
+					fold.ignored++
+				}
+			}
+			folds = append(folds, fold)
+		}
+		result = append(result, unchanged...)
+	}
+
+	prev := 0
+	for _, h := range findHunks(lines, context) {
+		addUnchanged(lines[prev:h.start])
+		result = append(result, diffLine{text: h.header(), hunkHeader: true})
+		result = append(result, lines[h.start:h.end]...)
+		prev = h.end
+	}
+	addUnchanged(lines[prev:])
+
+	return result, folds
+}
+
+// rowSelected expands the fold shown in the row
+func (tv *diffView) rowSelected(row, column int) {
+	if row < 1 || row > len(tv.rows) || tv.rows[row-1].fold < 0 {
+		return
+	}
+
+	tv.expandFold(tv.rows[row-1].fold)
+	tv.view.Select(row, 0)
+}
+
+// expandFold shows the lines of the fold
+func (tv *diffView) expandFold(fold int) {
+	tv.folds[fold].expanded = true
+	tv.layoutRows()
+	tv.render()
+}
+
+func (tv *diffView) SetBinary(binary *binaryDiff) {
+	tv.lines = nil
+	tv.folds = nil
+	tv.binary = binary
+
+	tv.findMatches()
+	tv.layoutRows()
+	tv.render()
+	tv.view.Select(1, 0).ScrollToBeginning()
+}
+
+func (tv *diffView) SetSplit(split bool) {
+	if tv.split == split {
+		return
+	}
+
+	tv.split = split
+	tv.layoutRows()
+	tv.render()
+	tv.view.ScrollToBeginning()
+	if len(tv.matches) > 0 {
+		tv.scrollToMatch()
+	}
+}
+
+func (tv *diffView) GetSearch() string {
+	return tv.search
+}
+
+func (tv *diffView) SetSearch(query string) {
+	if tv.search == query {
+		return
+	}
+
+	tv.search = query
+	tv.findMatches()
+	tv.render()
+	if len(tv.matches) > 0 {
+		tv.scrollToMatch()
+	}
+}
+
+func (tv *diffView) NextMatch(forward bool) {
+	if len(tv.matches) == 0 {
+		return
+	}
+
+	if forward {
+		tv.curMatch = (tv.curMatch + 1) % len(tv.matches)
+	} else {
+		tv.curMatch = (tv.curMatch - 1 + len(tv.matches)) % len(tv.matches)
+	}
+
+	tv.render()
+	tv.scrollToMatch()
+}
+
+// findMatches collects the occurrences of the search, ignoring case
+func (tv *diffView) findMatches() {
+	tv.matches = nil
+	tv.curMatch = 0
+	if tv.search == "" {
+		return
+	}
+
+	for idx, l := range tv.lines {
+		if l.hunkHeader {
+			continue
+		}
+
+		for offset := 0; ; {
+			start, end := indexFold(l.text[offset:], tv.search)
+			if start < 0 {
+				break
+			}
+
+			tv.matches = append(tv.matches, diffMatch{line: idx, start: offset + start, end: offset + end})
+			offset += end
+		}
+	}
+}
+
+// layoutRows assigns the lines to rows of the table.
+// The split mode pairs deleted lines with the added lines following them
+func (tv *diffView) layoutRows() {
+	tv.rows = nil
+	tv.lineRows = make([]int, len(tv.lines))
+
+	addRow := func(left, right int) {
+		tv.rows = append(tv.rows, diffRow{left: left, right: right, fold: -1})
+		// rows start after the header
+		for _, idx := range []int{left, right} {
+			if idx >= 0 {
+				tv.lineRows[idx] = len(tv.rows)
+			}
+		}
+	}
+
+	isChange := func(idx int, op diff.Operation) bool {
+		return idx < len(tv.lines) && !tv.lines[idx].hunkHeader && tv.lines[idx].op == op
+	}
+
+	foldAt := make(map[int]int)
+	for idx, f := range tv.folds {
+		if !f.expanded {
+			foldAt[f.start] = idx
+		}
+	}
+
+	for idx := 0; idx < len(tv.lines); {
+		if fold, ok := foldAt[idx]; ok {
+			tv.rows = append(tv.rows, diffRow{left: -1, right: -1, fold: fold})
+			for ; idx < tv.folds[fold].end; idx++ {
+				tv.lineRows[idx] = len(tv.rows)
+			}
+			continue
+		}
+
+		l := tv.lines[idx]
+		if !tv.split {
+			addRow(idx, -1)
+			idx++
+			continue
+		}
+		if l.hunkHeader || l.op == diff.Equal {
+			addRow(idx, idx)
+			idx++
+			continue
+		}
+
+		deleted := idx
+		for isChange(idx, diff.Delete) {
+			idx++
+		}
+		added := idx
+		for isChange(idx, diff.Add) {
+			idx++
+		}
+
+		numDeleted, numAdded := added - deleted, idx - added
+		for i := 0; i < numDeleted || i < numAdded; i++ {
+			left, right := -1, -1
+			if i < numDeleted {
+				left = deleted + i
+			}
+			if i < numAdded {
+				right = added + i
+			}
+			addRow(left, right)
+		}
+	}
+}
 
-func (tv *diffView) SetFilePatch(patch diff.FilePatch) {
+// render fills the table with the rows, highlighting the matches
+func (tv *diffView) render() {
 	tableView := tv.view
 	tableView.Clear()
 
-	if patch == nil {
+	title := DiffViewTitle
+	if tv.renamed != "" {
+		title += " " + tview.Escape(tv.renamed)
+	}
+	if tv.split {
+		title += " (split)"
+	}
+	if tv.whitespace != 0 {
+		title += " (" + tv.whitespace.String() + ")"
+	}
+	if tv.search != "" {
+		if len(tv.matches) > 0 {
+			title += fmt.Sprintf(" /%s match %d/%d", tview.Escape(tv.search), tv.curMatch+1, len(tv.matches))
+		} else {
+			title += fmt.Sprintf(" /%s no match", tview.Escape(tv.search))
+		}
+	}
+	tableView.SetTitle(title)
+
+	if tv.binary != nil {
+		tv.renderBinary()
+		return
+	}
+	if tv.lines == nil {
+		return
+	}
+
+	lineMatches := make(map[int][]int)
+	for idx, m := range tv.matches {
+		lineMatches[m.line] = append(lineMatches[m.line], idx)
+	}
+
+	if tv.split {
+		tv.renderSplit(lineMatches)
 		return
 	}
 
-	tableView.SetCell(0, 0, 
+	tableView.SetCell(0, diffColumnOld,
+		TableFormatting.Header(tview.NewTableCell("old").SetSelectable(false)))
+	tableView.SetCell(0, diffColumnNew,
+		TableFormatting.Header(tview.NewTableCell("new").SetSelectable(false)))
+	tableView.SetCell(0, diffColumnLine,
 		TableFormatting.Header(
 			tview.NewTableCell("line").SetSelectable(false)).
 		SetExpansion(1))
 
-	idx := 0
-	for _, c := range patch.Chunks() {
-		content := c.Content()
-		op := c.Type()
+	for idx, r := range tv.rows {
+		row := idx + 1
+		if r.fold >= 0 {
+			tableView.SetCell(row, diffColumnOld, tview.NewTableCell(""))
+			tableView.SetCell(row, diffColumnNew, tview.NewTableCell(""))
+			tableView.SetCell(row, diffColumnLine, tv.foldCell(r.fold))
+			continue
+		}
 
-		for _, l := range strings.Split(content, "\n") {
-			// since tview does not display tabs, expand tabs to string
-			l = strings.Replace(l, "\t", ExpandTabStr, -1)
+		l := tv.lines[r.left]
+		tableView.SetCell(row, diffColumnOld, lineNumberCell(l.oldLine))
+		tableView.SetCell(row, diffColumnNew, lineNumberCell(l.newLine))
 
-			var cell *tview.TableCell
-			switch op {
-			case diff.Equal:
-				cell = tview.NewTableCell(fmt.Sprintf(" %s", l))
-			case diff.Add:
-				cell = tview.NewTableCell(fmt.Sprintf("+%s", l))
-				cell.SetTextColor(LineColorInserted)
-			case diff.Delete:
-				cell = tview.NewTableCell(fmt.Sprintf("-%s", l))
-				cell.SetTextColor(LineColorDeleted)
+		const WithPrefix = true
+		tableView.SetCell(row, diffColumnLine, tv.lineCell(r.left, lineMatches[r.left], WithPrefix))
+	}
+}
+
+// renderSplit fills the table with old lines on the left, and new lines on the right
+func (tv *diffView) renderSplit(lineMatches map[int][]int) {
+	tableView := tv.view
+
+	// both sides share the width left by the line numbers
+	_, _, width, _ := tableView.GetInnerRect()
+	numberWidth := len(fmt.Sprintf("%d", len(tv.lines)))
+	textWidth := (width - 2*numberWidth - 3) / 2
+
+	for col, name := range []string{"old", "line", "new", "line"} {
+		tableView.SetCell(0, col,
+			TableFormatting.Header(tview.NewTableCell(name).SetSelectable(false)))
+	}
+
+	for idx, r := range tv.rows {
+		row := idx + 1
+		if r.fold >= 0 {
+			tableView.SetCell(row, splitColumnOld, tview.NewTableCell(""))
+			tableView.SetCell(row, splitColumnOldLine, tv.foldCell(r.fold))
+			tableView.SetCell(row, splitColumnNew, tview.NewTableCell(""))
+			tableView.SetCell(row, splitColumnNewLine, tview.NewTableCell("").SetExpansion(1))
+			continue
+		}
+
+		var oldLine, newLine int
+		if r.left >= 0 {
+			oldLine = tv.lines[r.left].oldLine
+		}
+		if r.right >= 0 && !tv.lines[r.right].hunkHeader {
+			newLine = tv.lines[r.right].newLine
+		}
+
+		const WithPrefix = false
+		left := tv.lineCell(r.left, lineMatches[r.left], WithPrefix)
+		right := tv.lineCell(r.right, lineMatches[r.right], WithPrefix)
+		if r.left >= 0 && tv.lines[r.left].hunkHeader {
+			// the header describes both sides
+			right = tview.NewTableCell("")
+		}
+		if textWidth > 0 {
+			// columns are as wide as their widest visible cell,
+			// so left cells are padded to keep both sides aligned
+			if pad := textWidth - tview.StringWidth(left.Text); pad > 0 {
+				left.SetText(left.Text + strings.Repeat(" ", pad))
 			}
+			left.SetMaxWidth(textWidth)
+			right.SetMaxWidth(textWidth)
+		}
+		right.SetExpansion(1)
 
-			tableView.SetCell(idx, 0, cell)
-			idx++
+		tableView.SetCell(row, splitColumnOld, lineNumberCell(oldLine))
+		tableView.SetCell(row, splitColumnOldLine, left)
+		tableView.SetCell(row, splitColumnNew, lineNumberCell(newLine))
+		tableView.SetCell(row, splitColumnNewLine, right)
+	}
+}
+
+// renderBinary fills the table with the description of both sides of the binary file
+func (tv *diffView) renderBinary() {
+	tableView := tv.view
+	b := tv.binary
+
+	tableView.SetTitle(DiffViewTitle + " " + tview.Escape(b.path) + " (binary)")
+
+	for col, name := range []string{"", "old", "new"} {
+		cell := TableFormatting.Header(tview.NewTableCell(name).SetSelectable(false))
+		if col > 0 {
+			cell.SetExpansion(1)
+		}
+		tableView.SetCell(0, col, cell)
+	}
+
+	describe := func(blob *binaryBlob, field string) string {
+		if blob == nil {
+			return "-"
+		}
+
+		switch field {
+		case "hash":
+			return blob.hash.String()
+		case "size":
+			return formatSize(blob.size)
+		case "type":
+			return blob.mime
+		case "dimensions":
+			if blob.width == 0 && blob.height == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d x %d", blob.width, blob.height)
+		}
+		return ""
+	}
+
+	for idx, field := range []string{"hash", "size", "type", "dimensions"} {
+		row := idx + 1
+		tableView.SetCell(row, 0, tview.NewTableCell(field).SetTextColor(LineNumberColor))
+
+		oldText, newText := describe(b.from, field), describe(b.to, field)
+		oldCell, newCell := tview.NewTableCell(tview.Escape(oldText)), tview.NewTableCell(tview.Escape(newText))
+		if oldText != newText {
+			oldCell.SetTextColor(LineColorDeleted)
+			newCell.SetTextColor(LineColorInserted)
+		}
+		tableView.SetCell(row, 1, oldCell)
+		tableView.SetCell(row, 2, newCell)
+	}
+}
+
+// foldCell creates the cell showing the fold
+func (tv *diffView) foldCell(fold int) *tview.TableCell {
+	f := tv.folds[fold]
+	text := fmt.Sprintf("%d unchanged lines", f.end - f.start - f.ignored)
+	if f.ignored > 0 {
+		// changed blank lines are not unchanged even if they are ignored
+		ignored := fmt.Sprintf("%d ignored blank line change", f.ignored)
+		if f.ignored > 1 {
+			ignored += "s"
+		}
+		if f.end - f.start == f.ignored {
+			text = ignored
+		} else {
+			text += ", " + ignored
+		}
+	}
+	return tview.NewTableCell("… " + text + " …").
+		SetTextColor(FoldColor)
+}
+
+// lineCell creates the cell showing the line at idx, empty if idx is -1.
+// withPrefix prefixes the line with +, - or a space as unified diffs do
+func (tv *diffView) lineCell(idx int, matchIndices []int, withPrefix bool) *tview.TableCell {
+	if idx < 0 {
+		return tview.NewTableCell("")
+	}
+
+	l := tv.lines[idx]
+	if l.hunkHeader {
+		return tview.NewTableCell(tview.Escape(l.text)).SetTextColor(LineColorHunkHeader)
+	}
+
+	text := tv.highlightLine(l, matchIndices)
+
+	var prefix string
+	if withPrefix {
+		switch l.op {
+		case diff.Equal:
+			prefix = " "
+		case diff.Add:
+			prefix = "+"
+		case diff.Delete:
+			prefix = "-"
+		}
+	}
+
+	// the background of the cell would cover the one of the tags,
+	// so the prefix is colored by a tag as the text is
+	color, background := tv.lineColors(l)
+	if prefix != "" {
+		prefix = colorTag(color, background) + prefix
+	}
+	return tview.NewTableCell(prefix + text).SetTextColor(color)
+}
+
+// lineColors returns the text and background colors of l.
+// The syntax takes the text color of highlighted lines,
+// so their changes are told by the background
+func (tv *diffView) lineColors(l diffLine) (tcell.Color, tcell.Color) {
+	color, background := tview.Styles.PrimaryTextColor, tview.Styles.PrimitiveBackgroundColor
+	switch {
+	case l.hunkHeader || l.op == diff.Equal:
+	case tv.highlighted && l.op == diff.Add:
+		background = LineBackgroundColorInserted
+	case tv.highlighted && l.op == diff.Delete:
+		background = LineBackgroundColorDeleted
+	case l.op == diff.Add:
+		color = LineColorInserted
+	case l.op == diff.Delete:
+		color = LineColorDeleted
+	}
+
+	return color, background
+}
+
+// lineNumberCell creates the gutter cell of a line number, empty if it is 0
+func lineNumberCell(number int) *tview.TableCell {
+	text := ""
+	if number > 0 {
+		text = fmt.Sprintf("%d", number)
+	}
+
+	return tview.NewTableCell(text).
+		SetAlign(tview.AlignRight).
+		SetTextColor(LineNumberColor)
+}
+
+// highlightLine escapes the text of l, and colors it by its syntax,
+// its changed words and the matches at the indices over them
+func (tv *diffView) highlightLine(l diffLine, matchIndices []int) string {
+	// the line is split where any match, change or syntax span starts or ends
+	bounds := []int{0, len(l.text)}
+	for _, idx := range matchIndices {
+		m := tv.matches[idx]
+		bounds = append(bounds, m.start, m.end)
+	}
+	for _, c := range l.changes {
+		bounds = append(bounds, c.start, c.end)
+	}
+	for _, span := range l.syntax {
+		bounds = append(bounds, span.start, span.end)
+	}
+	sort.Ints(bounds)
+
+	// tview applies a single color tag at a position, and does not reset
+	// colors reliably, so tags with both colors are written where they change
+	color, background := tv.lineColors(l)
+	prevTag := colorTag(color, background)
+
+	var b strings.Builder
+	for i := 0; i + 1 < len(bounds); i++ {
+		start, end := bounds[i], bounds[i+1]
+		if start == end {
+			continue
+		}
+
+		if tag := tv.segmentTag(l, matchIndices, start, color, background); tag != prevTag {
+			b.WriteString(tag)
+			prevTag = tag
+		}
+		b.WriteString(tview.Escape(l.text[start:end]))
+	}
+
+	return b.String()
+}
+
+// segmentTag returns the color tag of the part of l starting at start,
+// given the colors of the line.
+// Matches take over changed words, which keep the color of their syntax
+func (tv *diffView) segmentTag(l diffLine, matchIndices []int, start int, color, background tcell.Color) string {
+	for _, idx := range matchIndices {
+		m := tv.matches[idx]
+		if start >= m.start && start < m.end {
+			background := SearchMatchBackgroundColor
+			if idx == tv.curMatch {
+				background = CurrentMatchBackgroundColor
+			}
+			return colorTag(SearchMatchColor, background)
+		}
+	}
+
+	span, highlighted := syntaxAt(l.syntax, start)
+	for _, c := range l.changes {
+		if start >= c.start && start < c.end {
+			wordColor, wordBackground := WordColorChanged, WordBackgroundColorInserted
+			if l.op == diff.Delete {
+				wordBackground = WordBackgroundColorDeleted
+			}
+			if highlighted {
+				wordColor = span.color
+			}
+			return colorTag(wordColor, wordBackground)
+		}
+	}
+
+	if highlighted {
+		return colorTag(span.color, background)
+	}
+	return colorTag(color, background)
+}
+
+// colorTag returns the tview tag setting both colors
+func colorTag(color, background tcell.Color) string {
+	name := func(c tcell.Color) string {
+		if c == tcell.ColorDefault {
+			// unknown names are the default color
+			return "default"
+		}
+		return fmt.Sprintf("#%06x", c.Hex())
+	}
+
+	return fmt.Sprintf("[%s:%s]", name(color), name(background))
+}
+
+// syntaxAt returns the span of spans containing pos
+func syntaxAt(spans []syntaxSpan, pos int) (syntaxSpan, bool) {
+	for _, span := range spans {
+		if pos >= span.start && pos < span.end {
+			return span, true
 		}
 	}
-	tableView.ScrollToBeginning()
-}
\ No newline at end of file
+	return syntaxSpan{}, false
+}
+
+// scrollToMatch scrolls the line of the current match to the middle of the view
+func (tv *diffView) scrollToMatch() {
+	line := tv.matches[tv.curMatch].line
+	for idx, f := range tv.folds {
+		if !f.expanded && line >= f.start && line < f.end {
+			tv.expandFold(idx)
+			break
+		}
+	}
+
+	_, _, _, height := tv.view.GetInnerRect()
+	// rows start after the header
+	row := tv.lineRows[line]
+	tv.view.Select(row, 0)
+
+	offset := row - height/2
+	if offset < 0 {
+		offset = 0
+	}
+	tv.view.SetOffset(offset, 0)
+}
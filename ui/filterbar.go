@@ -0,0 +1,123 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// FilterBarWidth is the width of each field of the filter bar
+const FilterBarWidth = 24
+
+// FilterBarTitle is the title of the filter bar unless the filter is invalid
+const FilterBarTitle = "Filter history (dates like 2006-01-02 or 2 weeks ago)"
+
+// FilterBarView is a popup to edit the author and the dates the history is limited to
+type FilterBarView interface {
+	GetView() *tview.Form
+
+	// SetFilter fills the fields with the filter currently used
+	SetFilter(filter LogFilter)
+}
+
+type filterBarView struct {
+	top TopLevelView
+
+	view *tview.Form
+	author *tview.InputField
+	since *tview.InputField
+	until *tview.InputField
+}
+
+////////////////////////////////////////////////////////////
+// filterBarView functions
+////////////////////////////////////////////////////////////
+
+// NewFilterBarView creates an instance of FilterBarView
+func NewFilterBarView(top TopLevelView) FilterBarView {
+	fv := &filterBarView{
+		top: top,
+		author: tview.NewInputField().SetLabel("Author").SetFieldWidth(FilterBarWidth),
+		since: tview.NewInputField().SetLabel("Since").SetFieldWidth(FilterBarWidth),
+		until: tview.NewInputField().SetLabel("Until").SetFieldWidth(FilterBarWidth),
+	}
+
+	form := tview.NewForm().
+		SetHorizontal(true).
+		AddFormItem(fv.author).
+		AddFormItem(fv.since).
+		AddFormItem(fv.until).
+		AddButton("Apply", fv.apply).
+		AddButton("Clear", fv.clear).
+		SetCancelFunc(top.ClosePopup)
+
+	form.
+		SetBorder(true).
+		SetTitle(FilterBarTitle)
+
+	// apply the filter on Enter in any field, instead of moving to the next one
+	for _, field := range []*tview.InputField{fv.author, fv.since, fv.until} {
+		field.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyEnter {
+				fv.apply()
+				return nil
+			}
+			return event
+		})
+	}
+
+	fv.view = form
+
+	return fv
+}
+
+func (fv *filterBarView) GetView() *tview.Form {
+	return fv.view
+}
+
+func (fv *filterBarView) SetFilter(filter LogFilter) {
+	fv.view.SetTitle(FilterBarTitle)
+	fv.author.SetText(filter.Author)
+	fv.since.SetText(formatFilterDate(filter.Since))
+	fv.until.SetText(formatFilterDate(filter.Until))
+}
+
+func (fv *filterBarView) apply() {
+	filter, err := ParseLogFilter(fv.author.GetText(), fv.since.GetText(), fv.until.GetText())
+	if err != nil {
+		// keep the bar open to fix the filter
+		fv.view.SetTitle(tview.Escape(err.Error()))
+		return
+	}
+
+	fv.top.ClosePopup()
+	fv.top.NotifyFilterChange(filter)
+}
+
+func (fv *filterBarView) clear() {
+	fv.top.ClosePopup()
+	fv.top.NotifyFilterChange(LogFilter{})
+}
@@ -0,0 +1,120 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+// GotoPromptTitle is the title of the go to prompt unless the revision is invalid
+const GotoPromptTitle = "Go to (hash, ref, HEAD~5, main^2)"
+
+// GotoPromptView is a popup to enter the revision of a commit to select
+type GotoPromptView interface {
+	GetView() *tview.InputField
+
+	// Open clears the prompt
+	Open()
+}
+
+type gotoPromptView struct {
+	top TopLevelView
+	worker Worker
+	repo *git.Repository
+
+	view *tview.InputField
+}
+
+////////////////////////////////////////////////////////////
+// gotoPromptView functions
+////////////////////////////////////////////////////////////
+
+// NewGotoPromptView creates an instance of GotoPromptView
+func NewGotoPromptView(top TopLevelView, worker Worker, repo *git.Repository) GotoPromptView {
+	inputField := tview.NewInputField().
+		SetLabel("Revision: ")
+
+	inputField.
+		SetBorder(true).
+		SetTitle(GotoPromptTitle)
+
+	gv := &gotoPromptView{
+		top: top,
+		worker: worker,
+		repo: repo,
+		view: inputField,
+	}
+
+	inputField.SetDoneFunc(gv.done)
+
+	return gv
+}
+
+func (gv *gotoPromptView) GetView() *tview.InputField {
+	return gv.view
+}
+
+func (gv *gotoPromptView) Open() {
+	gv.view.SetText("")
+	gv.view.SetTitle(GotoPromptTitle)
+}
+
+func (gv *gotoPromptView) done(key tcell.Key) {
+	if key != tcell.KeyEnter {
+		// the revision is no longer looked for
+		gv.worker.Cancel(JobGoto)
+		gv.top.ClosePopup()
+		return
+	}
+
+	rev := gv.view.GetText()
+	if rev == "" {
+		return
+	}
+
+	// abbreviated hashes are looked for among all commits
+	gv.view.SetTitle(fmt.Sprintf("resolving %s…", tview.Escape(rev)))
+	repo := gv.repo
+	gv.worker.Submit(JobGoto, func(ctx context.Context) func() {
+		commit, err := resolveCommit(ctx, repo, rev)
+
+		return func() {
+			if err != nil {
+				// keep the prompt open to fix the revision
+				gv.view.SetTitle(tview.Escape(err.Error()))
+				return
+			}
+
+			gv.top.ClosePopup()
+			gv.top.SelectCommit(commit)
+		}
+	})
+}
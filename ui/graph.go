@@ -0,0 +1,252 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Symbols used to draw the commit graph
+const (
+	GraphCommit = '●'
+	GraphVertical = '│'
+	GraphHorizontal = '─'
+	GraphCross = '┼'
+	GraphMergeLeft = '┘'
+	GraphMergeRight = '└'
+	GraphForkLeft = '┐'
+	GraphForkRight = '┌'
+	GraphJoinLeft = '┤'
+	GraphJoinRight = '├'
+)
+
+// GraphColors are the colors lanes are drawn with, in the order of lanes
+var GraphColors = []tcell.Color{
+	tcell.ColorBlue,
+	tcell.ColorFuchsia,
+	tcell.ColorTeal,
+	tcell.ColorOlive,
+	tcell.ColorNavy,
+	tcell.ColorPurple,
+}
+
+// commitGraph assigns commits to lanes like git log --graph.
+// Commits must be added children first, which is the order the log is loaded in,
+// so the graph can be extended as more pages are loaded
+type commitGraph struct {
+	// lanes holds the hash of the commit each lane leads to,
+	// ZeroHash for unused lanes
+	lanes []plumbing.Hash
+}
+
+type graphCell struct {
+	symbol rune
+	lane int
+}
+
+// newCommitGraph creates an empty graph
+func newCommitGraph() *commitGraph {
+	return &commitGraph{}
+}
+
+// Next adds commit to the graph, and returns its row as a string with color tags
+func (g *commitGraph) Next(commit *object.Commit) string {
+	// the first lane leading to the commit gets it,
+	// the others end here
+	col := -1
+	var merging []int
+	for idx, h := range g.lanes {
+		if h != commit.Hash {
+			continue
+		}
+
+		if col < 0 {
+			col = idx
+		} else {
+			merging = append(merging, idx)
+		}
+	}
+
+	if col < 0 {
+		// no child has been seen, e.g. the tip of a branch
+		col = freeLane(g.lanes)
+		g.lanes = setLane(g.lanes, col, commit.Hash)
+	}
+
+	next := make([]plumbing.Hash, len(g.lanes))
+	copy(next, g.lanes)
+	for _, idx := range merging {
+		next[idx] = plumbing.ZeroHash
+	}
+
+	// the first parent continues the lane of the commit,
+	// other parents join their lane or start a new one
+	var forks, joins []int
+	if len(commit.ParentHashes) == 0 {
+		next[col] = plumbing.ZeroHash
+	} else {
+		next[col] = commit.ParentHashes[0]
+		for _, parent := range commit.ParentHashes[1:] {
+			if idx := laneOf(next, parent); idx >= 0 && idx != col {
+				joins = append(joins, idx)
+			} else if idx < 0 {
+				idx = freeLane(next)
+				next = setLane(next, idx, parent)
+				forks = append(forks, idx)
+			}
+		}
+	}
+
+	width := len(g.lanes)
+	if len(next) > width {
+		width = len(next)
+	}
+
+	// each lane takes two columns, the lane and the space to the right of it
+	cells := make([]graphCell, width*2)
+	for idx := range cells {
+		cells[idx] = graphCell{symbol: ' ', lane: idx / 2}
+	}
+	for idx, h := range g.lanes {
+		if h != plumbing.ZeroHash {
+			cells[idx*2].symbol = GraphVertical
+		}
+	}
+
+	connect := func(lane int, left, right rune) {
+		from, to := col*2, lane*2
+		symbol := right
+		if lane < col {
+			from, to = to, from
+			symbol = left
+		}
+
+		for pos := from + 1; pos < to; pos++ {
+			cells[pos].lane = lane
+			if cells[pos].symbol == GraphVertical {
+				cells[pos].symbol = GraphCross
+			} else {
+				cells[pos].symbol = GraphHorizontal
+			}
+		}
+		cells[lane*2].symbol = symbol
+	}
+
+	for _, lane := range merging {
+		connect(lane, GraphMergeRight, GraphMergeLeft)
+	}
+	for _, lane := range forks {
+		connect(lane, GraphForkRight, GraphForkLeft)
+	}
+	for _, lane := range joins {
+		connect(lane, GraphJoinRight, GraphJoinLeft)
+	}
+	cells[col*2].symbol = GraphCommit
+
+	g.lanes = trimLanes(next)
+
+	return renderGraphCells(cells)
+}
+
+// Skip passes commit, which is not listed, without a row.
+// The lanes leading to it lead to its first parent instead,
+// and its other parents start lanes of their own when they are reached like git log --graph --no-merges
+func (g *commitGraph) Skip(commit *object.Commit) {
+	parent := plumbing.ZeroHash
+	if len(commit.ParentHashes) > 0 {
+		parent = commit.ParentHashes[0]
+	}
+
+	for idx, h := range g.lanes {
+		if h == commit.Hash {
+			g.lanes[idx] = parent
+		}
+	}
+	g.lanes = trimLanes(g.lanes)
+}
+
+// renderGraphCells converts cells to a string, coloring each lane
+func renderGraphCells(cells []graphCell) string {
+	for len(cells) > 0 && cells[len(cells)-1].symbol == ' ' {
+		cells = cells[:len(cells)-1]
+	}
+
+	var sb strings.Builder
+	lastLane := -1
+	for _, c := range cells {
+		if c.symbol != ' ' && c.lane != lastLane {
+			color := GraphColors[c.lane % len(GraphColors)]
+			fmt.Fprintf(&sb, "[#%06x]", color.Hex())
+			lastLane = c.lane
+		}
+		sb.WriteRune(c.symbol)
+	}
+	sb.WriteString("[-]")
+
+	return sb.String()
+}
+
+// laneOf returns the lane leading to hash, or -1
+func laneOf(lanes []plumbing.Hash, hash plumbing.Hash) int {
+	for idx, h := range lanes {
+		if h == hash {
+			return idx
+		}
+	}
+	return -1
+}
+
+// freeLane returns the first unused lane
+func freeLane(lanes []plumbing.Hash) int {
+	for idx, h := range lanes {
+		if h == plumbing.ZeroHash {
+			return idx
+		}
+	}
+	return len(lanes)
+}
+
+// setLane assigns hash to the lane, growing lanes if needed
+func setLane(lanes []plumbing.Hash, lane int, hash plumbing.Hash) []plumbing.Hash {
+	for len(lanes) <= lane {
+		lanes = append(lanes, plumbing.ZeroHash)
+	}
+	lanes[lane] = hash
+	return lanes
+}
+
+// trimLanes removes unused lanes at the end
+func trimLanes(lanes []plumbing.Hash) []plumbing.Hash {
+	for len(lanes) > 0 && lanes[len(lanes)-1] == plumbing.ZeroHash {
+		lanes = lanes[:len(lanes)-1]
+	}
+	return lanes
+}
@@ -0,0 +1,87 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// MaxGrepHits is the number of hits grep stops at
+const MaxGrepHits = 1000
+
+// grepHit is a line of a file matching grep
+type grepHit struct {
+	path string
+	// line starts from 1
+	line int
+	text string
+}
+
+// grepTree returns the lines of text files in tree matching re, in the order of the tree.
+// It stops after MaxGrepHits hits
+func grepTree(ctx context.Context, tree *object.Tree, re *regexp.Regexp) ([]grepHit, error) {
+	var hits []grepHit
+
+	files := tree.Files()
+	defer files.Close()
+
+	for len(hits) < MaxGrepHits {
+		if err := ctx.Err(); err != nil {
+			return hits, err
+		}
+
+		file, err := files.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return hits, err
+		}
+
+		if binary, err := file.IsBinary(); err != nil || binary {
+			continue
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return hits, err
+		}
+
+		for idx, line := range strings.Split(contents, "\n") {
+			if re.MatchString(line) {
+				hits = append(hits, grepHit{path: file.Name, line: idx + 1, text: line})
+				if len(hits) >= MaxGrepHits {
+					break
+				}
+			}
+		}
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,295 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// GrepView is a popup to search the files of a commit,
+// showing the file of the selected hit next to the hits
+type GrepView interface {
+	GetView() tview.Primitive
+
+	// Open starts searching the files of commit
+	Open(commit *object.Commit)
+}
+
+type grepView struct {
+	top TopLevelView
+	worker Worker
+
+	view *tview.Flex
+	input *tview.InputField
+	hitsView *tview.Table
+	fileView *tview.Table
+
+	commit *object.Commit
+	hits []grepHit
+}
+
+const (
+	// GrepLineNumberColor is the color of line numbers
+	GrepLineNumberColor = tcell.ColorGray
+	// GrepPathColor is the color of paths of hits
+	GrepPathColor = tcell.ColorTeal
+)
+
+////////////////////////////////////////////////////////////
+// grepView functions
+////////////////////////////////////////////////////////////
+
+// NewGrepView creates an instance of GrepView
+func NewGrepView(top TopLevelView, worker Worker) GrepView {
+	input := tview.NewInputField().
+		SetLabel("Pattern: ")
+
+	hitsView := tview.NewTable().
+		SetSelectable(
+			true,		// rows
+			false,		// columns
+		)
+	hitsView.
+		SetBorder(true).
+		SetTitle("Hits")
+
+	fileView := tview.NewTable().
+		SetSelectable(
+			true,		// rows
+			false,		// columns
+		)
+	fileView.
+		SetBorder(true).
+		SetTitle("File")
+
+	view := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(tview.NewFlex().
+			AddItem(hitsView, 0, 1, false).
+			AddItem(fileView, 0, 1, false), 0, 1, false)
+
+	view.
+		SetBorder(true).
+		SetTitle("Grep")
+
+	gv := &grepView{
+		top: top,
+		worker: worker,
+		view: view,
+		input: input,
+		hitsView: hitsView,
+		fileView: fileView,
+	}
+
+	input.SetDoneFunc(gv.inputDone)
+	hitsView.SetSelectionChangedFunc(gv.hitSelected)
+	hitsView.SetSelectedFunc(func(row, column int) {
+		gv.top.SetFocus(gv.fileView)
+	})
+	hitsView.SetDoneFunc(gv.done)
+	fileView.SetDoneFunc(gv.done)
+
+	// Tab moves between the pattern, the hits and the file
+	for _, p := range []*tview.Box{input.Box, hitsView.Box, fileView.Box} {
+		p.SetInputCapture(gv.moveFocus)
+	}
+
+	return gv
+}
+
+// moveFocus moves the focus on Tab and Backtab
+func (gv *grepView) moveFocus(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyTab && event.Key() != tcell.KeyBacktab {
+		return event
+	}
+
+	order := []tview.Primitive{gv.input, gv.hitsView, gv.fileView}
+	next := 0
+	for idx, p := range order {
+		if p.GetFocusable().HasFocus() {
+			next = idx + 1
+			if event.Key() == tcell.KeyBacktab {
+				next = idx - 1 + len(order)
+			}
+		}
+	}
+	gv.top.SetFocus(order[next % len(order)])
+
+	return nil
+}
+
+func (gv *grepView) GetView() tview.Primitive {
+	return gv.view
+}
+
+func (gv *grepView) Open(commit *object.Commit) {
+	if gv.commit != commit {
+		gv.commit = commit
+		gv.setHits(nil)
+	}
+
+	gv.view.SetTitle(fmt.Sprintf("Grep %s", shortHash(commit)))
+	gv.top.SetFocus(gv.input)
+}
+
+func (gv *grepView) done(key tcell.Key) {
+	if key == tcell.KeyEscape {
+		gv.worker.Cancel(JobGrep)
+		gv.worker.Cancel(JobGrepFile)
+		gv.top.ClosePopup()
+	}
+}
+
+func (gv *grepView) inputDone(key tcell.Key) {
+	if key != tcell.KeyEnter {
+		gv.done(key)
+		return
+	}
+
+	pattern := gv.input.GetText()
+	if pattern == "" {
+		return
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		gv.hitsView.SetTitle(tview.Escape(fmt.Sprintf("Invalid pattern: %v", err)))
+		return
+	}
+
+	commit := gv.commit
+	gv.hitsView.SetTitle("Hits (searching…)")
+	gv.worker.Submit(JobGrep, func(ctx context.Context) func() {
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil
+		}
+
+		hits, err := grepTree(ctx, tree, re)
+
+		return func() {
+			gv.setHits(hits)
+			if err != nil {
+				gv.hitsView.SetTitle(tview.Escape(fmt.Sprintf("Hits (failed: %v)", err)))
+			}
+			if len(hits) > 0 {
+				gv.top.SetFocus(gv.hitsView)
+			}
+		}
+	})
+}
+
+// setHits fills the hits table, and shows the file of the first one
+func (gv *grepView) setHits(hits []grepHit) {
+	gv.hits = hits
+	gv.worker.Cancel(JobGrepFile)
+
+	gv.hitsView.Clear()
+	gv.fileView.Clear()
+	gv.fileView.SetTitle("File")
+
+	title := fmt.Sprintf("Hits (%d)", len(hits))
+	if len(hits) >= MaxGrepHits {
+		title = fmt.Sprintf("Hits (first %d)", MaxGrepHits)
+	}
+	gv.hitsView.SetTitle(title)
+
+	for idx, hit := range hits {
+		gv.hitsView.SetCell(idx, 0,
+			tview.NewTableCell(tview.Escape(hit.path)).
+				SetTextColor(GrepPathColor))
+		gv.hitsView.SetCell(idx, 1,
+			tview.NewTableCell(fmt.Sprintf("%d", hit.line)).
+				SetTextColor(GrepLineNumberColor).
+				SetAlign(tview.AlignRight))
+		gv.hitsView.SetCell(idx, 2,
+			tview.NewTableCell(tview.Escape(expandTabs(strings.TrimSpace(hit.text)))).
+				SetExpansion(1))
+	}
+
+	gv.hitsView.Select(0, 0).ScrollToBeginning()
+	if len(hits) > 0 {
+		// Select does not notify the selection change
+		gv.hitSelected(0, 0)
+	}
+}
+
+// hitSelected shows the file of the hit at row, scrolled to its line
+func (gv *grepView) hitSelected(row, column int) {
+	if row < 0 || row >= len(gv.hits) {
+		return
+	}
+
+	hit := gv.hits[row]
+	commit := gv.commit
+	gv.worker.Submit(JobGrepFile, func(ctx context.Context) func() {
+		file, err := commit.File(hit.path)
+		if err != nil {
+			return nil
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return nil
+		}
+
+		return func() {
+			gv.showFile(hit, strings.Split(contents, "\n"))
+		}
+	})
+}
+
+func (gv *grepView) showFile(hit grepHit, lines []string) {
+	gv.fileView.Clear()
+	gv.fileView.SetTitle(tview.Escape(hit.path))
+
+	for idx, line := range lines {
+		gv.fileView.SetCell(idx, 0,
+			tview.NewTableCell(fmt.Sprintf("%d", idx+1)).
+				SetTextColor(GrepLineNumberColor).
+				SetAlign(tview.AlignRight))
+		gv.fileView.SetCell(idx, 1,
+			tview.NewTableCell(tview.Escape(expandTabs(line))).
+				SetExpansion(1))
+	}
+
+	// select the line of the hit, and scroll it to the middle
+	row := hit.line - 1
+	_, _, _, height := gv.fileView.GetInnerRect()
+	offset := row - height/2
+	if offset < 0 {
+		offset = 0
+	}
+	gv.fileView.Select(row, 0).SetOffset(offset, 0)
+}
@@ -0,0 +1,141 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+)
+
+// HunkContextLines is the number of unchanged lines a hunk includes
+// around the changes, as git does by default
+const HunkContextLines = 3
+
+// hunk is a range of lines having changes, and context around them
+type hunk struct {
+	// start and end are the indices of the first and past the last line
+	start int
+	end int
+
+	oldStart int
+	oldCount int
+	newStart int
+	newCount int
+}
+
+// header returns the @@ line of the hunk
+func (h hunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@",
+		formatHunkRange(h.oldStart, h.oldCount),
+		formatHunkRange(h.newStart, h.newCount))
+}
+
+// formatHunkRange formats a range of lines as git does,
+// leaving out the count of a single line
+func formatHunkRange(start, count int) string {
+	switch count {
+	case 0:
+		// an empty range starts after the line preceding it
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// changed returns true if l is a change hunks are made around
+func (l diffLine) changed() bool {
+	return l.op != diff.Equal && !l.ignored
+}
+
+// findHunks groups changed lines into hunks with context lines around them.
+// Changes separated by at most twice the context are in the same hunk
+func findHunks(lines []diffLine, context int) []hunk {
+	if context < 0 {
+		context = 0
+	}
+
+	var hunks []hunk
+	for idx := 0; idx < len(lines); idx++ {
+		if !lines[idx].changed() {
+			continue
+		}
+
+		start := idx - context
+		if start < 0 {
+			start = 0
+		}
+
+		// extend the hunk while the next change is close enough
+		last := idx
+		for next := idx + 1; next < len(lines) && next <= last + 2*context + 1; next++ {
+			if lines[next].changed() {
+				last = next
+			}
+		}
+
+		end := last + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		hunks = append(hunks, newHunk(lines, start, end))
+		idx = end - 1
+	}
+
+	return hunks
+}
+
+// newHunk creates the hunk of lines from start to end
+func newHunk(lines []diffLine, start, end int) hunk {
+	h := hunk{start: start, end: end}
+
+	// lines added at the start have no old line number, and the other way around
+	h.oldStart, h.newStart = 1, 1
+	for idx := start - 1; idx >= 0; idx-- {
+		if lines[idx].oldLine > 0 {
+			h.oldStart = lines[idx].oldLine + 1
+			break
+		}
+	}
+	for idx := start - 1; idx >= 0; idx-- {
+		if lines[idx].newLine > 0 {
+			h.newStart = lines[idx].newLine + 1
+			break
+		}
+	}
+
+	for _, l := range lines[start:end] {
+		if l.op != diff.Add {
+			h.oldCount++
+		}
+		if l.op != diff.Delete {
+			h.newCount++
+		}
+	}
+
+	return h
+}
@@ -0,0 +1,149 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+)
+
+// testDiffLines makes numbered lines of ops, where '=' is unchanged, '-' deleted and '+' added
+func testDiffLines(ops string) []diffLine {
+	var lines []diffLine
+	oldLine, newLine := 1, 1
+	for _, op := range ops {
+		switch op {
+		case '=':
+			lines = append(lines, diffLine{op: diff.Equal, oldLine: oldLine, newLine: newLine})
+			oldLine++
+			newLine++
+		case '-':
+			lines = append(lines, diffLine{op: diff.Delete, oldLine: oldLine})
+			oldLine++
+		case '+':
+			lines = append(lines, diffLine{op: diff.Add, newLine: newLine})
+			newLine++
+		}
+	}
+	return lines
+}
+
+func TestFindHunks(t *testing.T) {
+	tests := []struct {
+		name string
+		ops string
+		context int
+		// hunks are the start and end of each hunk
+		hunks [][2]int
+		headers []string
+	}{
+		{name: "no changes", ops: "=====", context: 3},
+		{name: "context 0", ops: "===-===", context: 0,
+			hunks: [][2]int{{3, 4}}, headers: []string{"@@ -4 +3,0 @@"}},
+		{name: "negative context", ops: "===-===", context: -3,
+			hunks: [][2]int{{3, 4}}, headers: []string{"@@ -4 +3,0 @@"}},
+		{name: "context larger than the lines", ops: "=+=", context: 3,
+			hunks: [][2]int{{0, 3}}, headers: []string{"@@ -1,2 +1,3 @@"}},
+		{name: "separate hunks", ops: "-=====+", context: 1,
+			hunks: [][2]int{{0, 2}, {5, 7}}, headers: []string{"@@ -1,2 +1 @@", "@@ -6 +5,2 @@"}},
+		{name: "adjacent hunks merge", ops: "-==+", context: 1,
+			hunks: [][2]int{{0, 4}}, headers: []string{"@@ -1,3 +1,3 @@"}},
+		{name: "context larger than the gap", ops: "=-=====+=", context: 3,
+			hunks: [][2]int{{0, 9}}, headers: []string{"@@ -1,8 +1,8 @@"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var hunks [][2]int
+			var headers []string
+			for _, h := range findHunks(testDiffLines(test.ops), test.context) {
+				hunks = append(hunks, [2]int{h.start, h.end})
+				headers = append(headers, h.header())
+			}
+
+			if !reflect.DeepEqual(hunks, test.hunks) {
+				t.Errorf("hunks are %v, want %v", hunks, test.hunks)
+			}
+			if !reflect.DeepEqual(headers, test.headers) {
+				t.Errorf("headers are %v, want %v", headers, test.headers)
+			}
+		})
+	}
+}
+
+func TestFoldLines(t *testing.T) {
+	tests := []struct {
+		name string
+		ops string
+		context int
+		// shown are the ops of the lines folded into, with 'h' for headers
+		shown string
+		folds []diffFold
+	}{
+		{name: "no changes", ops: "=====", context: 3,
+			shown: "=====", folds: []diffFold{{start: 0, end: 5}}},
+		{name: "context 0", ops: "===-===", context: 0,
+			shown: "===h-===", folds: []diffFold{{start: 0, end: 3}, {start: 5, end: 8}}},
+		{name: "negative context", ops: "===-===", context: -3,
+			shown: "===h-===", folds: []diffFold{{start: 0, end: 3}, {start: 5, end: 8}}},
+		{name: "single unchanged line is not folded", ops: "=-=", context: 0,
+			shown: "=h-="},
+		{name: "separate hunks", ops: "-=====+", context: 1,
+			shown: "h-====h=+", folds: []diffFold{{start: 3, end: 6}}},
+		{name: "adjacent hunks merge", ops: "-==+", context: 1,
+			shown: "h-==+"},
+		{name: "context larger than the gap", ops: "=-=====+=", context: 3,
+			shown: "h=-=====+="},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines, folds := foldLines(testDiffLines(test.ops), test.context)
+
+			var shown []rune
+			for _, l := range lines {
+				switch {
+				case l.hunkHeader:
+					shown = append(shown, 'h')
+				case l.op == diff.Delete:
+					shown = append(shown, '-')
+				case l.op == diff.Add:
+					shown = append(shown, '+')
+				default:
+					shown = append(shown, '=')
+				}
+			}
+
+			if string(shown) != test.shown {
+				t.Errorf("lines are %s, want %s", string(shown), test.shown)
+			}
+			if !reflect.DeepEqual(folds, test.folds) {
+				t.Errorf("folds are %v, want %v", folds, test.folds)
+			}
+		})
+	}
+}
@@ -25,21 +25,105 @@
 package ui
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
 	"github.com/rivo/tview"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
+// LoadMoreThreshold is the number of rows from the bottom of the list
+// at which the next page of commits is requested
+const LoadMoreThreshold int = 10
+
+// LoadingMoreText is shown in the last row while more commits can be loaded
+const LoadingMoreText = "loading more…"
+
 // CommitListView is a view to list commits
 type CommitListView interface {
 	GetView() *tview.Table
+
+	// commits can be searched by message, author or hash prefix
+	Searchable
+
+	// AppendCommits adds commits at the end of the list
+	// hasMore tells whether more commits can be loaded later
+	AppendCommits(commits []*object.Commit, hasMore bool)
+
+	// Reset removes all commits to show the history of rev
+	// limited to paths
+	Reset(rev string, paths []string)
+
+	// SetFilters sets the descriptions of the filters the history is limited by,
+	// which are shown in the title, and whether they leave out commits
+	// between the listed ones, which disconnects the graph
+	SetFilters(filters []string, dropsCommits bool)
+
+	// SetStatus sets a note on the loading of the history shown in the title,
+	// e.g. why it has ended early. Reset clears it
+	SetStatus(status string)
+
+	// SetProgress sets the text shown while more commits are loaded,
+	// LoadingMoreText if empty
+	SetProgress(text string)
+
+	// SelectCommit selects commit if it is listed, and returns whether it is
+	SelectCommit(commit *object.Commit) bool
+
+	// SetShowMerges sets whether merge commits are listed
+	SetShowMerges(show bool)
+
+	// SetDecorations sets the refs shown next to the commits they point to
+	SetDecorations(decorations map[plumbing.Hash][]refEntry)
+
+	// SetMarks sets the marks and bookmarks tagging the commits they point to
+	SetMarks(marks map[plumbing.Hash][]string)
+
+	// SetRelativeDate sets whether dates are shown relative to now
+	SetRelativeDate(relative bool)
+
+	// SortByNextColumn sorts commits by the next sortable column,
+	// going back to the log order after the last one
+	SortByNextColumn()
+
+	// ReverseSort reverses the sort order
+	ReverseSort()
 }
 
 type commitListView struct {
 	top TopLevelView
 
 	view *tview.Table
+	columns []CommitColumn
+	rev string
+	paths []string
+	filters []string
+	dropsCommits bool
+	status string
+	progress string
+
 	commits []*object.Commit
-	noMergeCommits []*object.Commit
+	// graph is extended as commits are loaded,
+	// and graphRows holds the row of the graph drawn for each commit
+	graph *commitGraph
+	graphRows map[plumbing.Hash]string
+	decorations map[plumbing.Hash][]refEntry
+	marks map[plumbing.Hash][]string
+	// shownCommits are the commits having a row in the table
+	shownCommits []*object.Commit
+	hasMore bool
+
+	showMerges bool
+	relativeDate bool
+	// sortColumn is empty to keep the log order
+	sortColumn CommitColumn
+	sortDescending bool
+
+	search string
 }
 
 ////////////////////////////////////////////////////////////
@@ -47,8 +131,11 @@ type commitListView struct {
 ////////////////////////////////////////////////////////////
 
 // NewCommitListView creates an instance of CommitListView
-func NewCommitListView(top TopLevelView, commits []*object.Commit) CommitListView {
-	tableColumns := []string{ "hash", "message" }
+// DefaultCommitColumns are shown if columns is empty
+func NewCommitListView(top TopLevelView, commits []*object.Commit, hasMore bool, columns []CommitColumn) CommitListView {
+	if len(columns) == 0 {
+		columns = DefaultCommitColumns
+	}
 
 	tableView := tview.NewTable().
 		SetBorders(false).
@@ -60,67 +147,555 @@ func NewCommitListView(top TopLevelView, commits []*object.Commit) CommitListVie
 		SetBorder(true).
 		SetTitle("Commits")
 
-	for i, col := range tableColumns {
-		tableView.SetCell(
-			0, i,
-			TableFormatting.Header(
-				tview.NewTableCell(col).
-					SetSelectable(false),
-			),
+	cv := commitListView{
+		top: top,
+		view: tableView,
+		columns: append([]CommitColumn{columnMarks}, columns...),
+		graph: newCommitGraph(),
+		graphRows: make(map[plumbing.Hash]string),
+	}
+
+	cv.setHeader()
+	cv.appendRows(commits, hasMore)
+
+	tableView.SetSelectionChangedFunc(cv.selectionChanged)
+
+	return &cv
+
+}
+
+func (cv *commitListView) GetView() *tview.Table {
+	return cv.view
+}
+
+func (cv *commitListView) Reset(rev string, paths []string) {
+	cv.clearRows()
+
+	cv.rev = rev
+	cv.paths = paths
+	cv.commits = nil
+	cv.graph = newCommitGraph()
+	cv.graphRows = make(map[plumbing.Hash]string)
+	cv.hasMore = false
+	cv.status = ""
+	cv.progress = ""
+	cv.updateTitle()
+
+	const HasMore = true
+	cv.appendRows(nil, HasMore)
+
+	cv.view.Select(0, 0).ScrollToBeginning()
+}
+
+func (cv *commitListView) SetFilters(filters []string, dropsCommits bool) {
+	cv.filters = filters
+	cv.dropsCommits = dropsCommits
+	cv.updateTitle()
+}
+
+func (cv *commitListView) SetStatus(status string) {
+	cv.status = status
+	cv.updateTitle()
+}
+
+func (cv *commitListView) SetProgress(text string) {
+	cv.progress = text
+	if cv.hasMore {
+		cv.removeLoadingRow()
+		cv.addLoadingRow()
+	}
+}
+
+func (cv *commitListView) SetShowMerges(show bool) {
+	if cv.showMerges == show {
+		return
+	}
+
+	cv.showMerges = show
+	cv.rebuildGraph()
+	cv.rebuildRows()
+}
+
+func (cv *commitListView) SetDecorations(decorations map[plumbing.Hash][]refEntry) {
+	cv.decorations = decorations
+	cv.rebuildRows()
+}
+
+func (cv *commitListView) SetMarks(marks map[plumbing.Hash][]string) {
+	cv.marks = marks
+	cv.rebuildRows()
+}
+
+func (cv *commitListView) SetRelativeDate(relative bool) {
+	if cv.relativeDate == relative {
+		return
+	}
+
+	cv.relativeDate = relative
+	cv.rebuildRows()
+}
+
+func (cv *commitListView) SortByNextColumn() {
+	var next CommitColumn
+	found := cv.sortColumn == ""
+	for _, column := range sortableColumns {
+		if !cv.hasColumn(column) {
+			continue
+		}
+
+		if found {
+			next = column
+			break
+		}
+		found = column == cv.sortColumn
+	}
+
+	cv.sortColumn = next
+	cv.sortDescending = next == ColumnDate
+	cv.updateTitle()
+	cv.rebuildRows()
+}
+
+func (cv *commitListView) ReverseSort() {
+	if cv.sortColumn == "" {
+		return
+	}
+
+	cv.sortDescending = !cv.sortDescending
+	cv.updateTitle()
+	cv.rebuildRows()
+}
+
+func (cv *commitListView) GetSearch() string {
+	return cv.search
+}
+
+func (cv *commitListView) SetSearch(query string) {
+	if cv.search == query {
+		return
+	}
+
+	cv.search = query
+	cv.updateTitle()
+	cv.updateSearchedCells()
+
+	if query == "" {
+		return
+	}
+
+	row, _ := cv.view.GetSelection()
+	if idx := cv.findMatch(row-1, true); idx >= 0 {
+		cv.selectShown(idx)
+	}
+}
+
+func (cv *commitListView) NextMatch(forward bool) {
+	if cv.search == "" {
+		return
+	}
+
+	row, _ := cv.view.GetSelection()
+	start := row
+	if !forward {
+		start = row - 2
+	}
+
+	// rows start after the header
+	if idx := cv.findMatch(start, forward); idx >= 0 {
+		cv.selectShown(idx)
+	}
+}
+
+// findMatch returns the index of the first shown commit matching the search
+// from start in the direction, wrapping around, or -1 if there is none
+func (cv *commitListView) findMatch(start int, forward bool) int {
+	count := len(cv.shownCommits)
+	if count == 0 {
+		return -1
+	}
+
+	step := 1
+	if !forward {
+		step = -1
+	}
+
+	for i := 0; i < count; i++ {
+		idx := ((start + i*step) % count + count) % count
+		if cv.commitMatches(cv.shownCommits[idx]) {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// commitMatches returns true if the search is highlighted in a column shown for the commit
+func (cv *commitListView) commitMatches(commit *object.Commit) bool {
+	for _, column := range cv.columns {
+		switch column {
+		case ColumnHash:
+			if prefixMatches(shortHash(commit), cv.search) {
+				return true
+			}
+		case ColumnMessage:
+			if textMatches(commitSubject(commit), cv.search) {
+				return true
+			}
+		case ColumnAuthor:
+			if textMatches(commit.Author.Name, cv.search) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// updateSearchedCells highlights the search again in the columns searched,
+// leaving the other cells as they are
+func (cv *commitListView) updateSearchedCells() {
+	now := time.Now()
+	for idx, commit := range cv.shownCommits {
+		for col, column := range cv.columns {
+			switch column {
+			case ColumnHash, ColumnMessage, ColumnAuthor:
+				cv.view.SetCell(idx+1, col, cv.makeCell(column, commit, now))
+			}
+		}
+	}
+}
+
+func (cv *commitListView) SelectCommit(commit *object.Commit) bool {
+	for idx, c := range cv.shownCommits {
+		if c.Hash == commit.Hash {
+			cv.selectShown(idx)
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectShown selects the shown commit at idx
+func (cv *commitListView) selectShown(idx int) {
+	cv.view.Select(idx+1, 0)
+	// Select does not notify the selection change
+	cv.selectionChanged(idx+1, 0)
+}
+
+func (cv *commitListView) AppendCommits(commits []*object.Commit, hasMore bool) {
+	cv.appendRows(commits, hasMore)
+
+	// keep loading while the selection is still close to the bottom,
+	// e.g. when the page consisted of merges only
+	row, _ := cv.view.GetSelection()
+	cv.loadMoreIfNeeded(row - 1)
+}
+
+// hasColumn returns true if the column is shown
+func (cv *commitListView) hasColumn(column CommitColumn) bool {
+	for _, c := range cv.columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// setHeader adds the header row
+func (cv *commitListView) setHeader() {
+	// the message column takes the remaining space,
+	// or the last column if there is no message column
+	expandIdx := len(cv.columns) - 1
+	for i, column := range cv.columns {
+		if column == ColumnMessage {
+			expandIdx = i
+		}
+	}
+
+	for i, column := range cv.columns {
+		cell := TableFormatting.Header(
+			tview.NewTableCell(string(column)).
+				SetSelectable(false),
 		)
+
+		if i == expandIdx {
+			cell.SetExpansion(1)
+		}
+		cv.view.SetCell(0, i, cell)
+	}
+}
+
+func (cv *commitListView) updateTitle() {
+	title := "Commits"
+	if len(cv.paths) > 0 {
+		rev := cv.rev
+		if rev == "" {
+			rev = string(plumbing.HEAD)
+		}
+		title = fmt.Sprintf("Commits (%s -- %s)",
+			tview.Escape(shortRevision(rev)), tview.Escape(strings.Join(cv.paths, " ")))
+	} else if cv.rev != "" {
+		title = fmt.Sprintf("Commits (%s)", tview.Escape(shortRevision(cv.rev)))
+	}
+
+	for _, filter := range cv.filters {
+		title += " " + tview.Escape(filter)
+	}
+
+	if cv.status != "" {
+		title += " " + tview.Escape(cv.status)
+	}
+
+	if cv.search != "" {
+		title += fmt.Sprintf(" /%s", tview.Escape(cv.search))
+	}
+
+	if cv.sortColumn != "" {
+		order := "↑"
+		if cv.sortDescending {
+			order = "↓"
+		}
+		title += fmt.Sprintf(" sorted by %s %s", cv.sortColumn, order)
+	}
+
+	cv.view.SetTitle(title)
+}
+
+// appendRows adds commits, and a row for each commit shown
+func (cv *commitListView) appendRows(commits []*object.Commit, hasMore bool) {
+	wasEmpty := len(cv.shownCommits) == 0
+	cv.commits = append(cv.commits, commits...)
+	cv.extendGraph(commits)
+
+	if cv.sortColumn != "" {
+		// new commits can be placed anywhere
+		cv.hasMore = hasMore
+		cv.rebuildRows()
+		return
+	}
+
+	if cv.hasMore {
+		cv.removeLoadingRow()
+	}
+
+	cv.addRows(commits)
+
+	cv.hasMore = hasMore
+	if hasMore {
+		cv.addLoadingRow()
+	}
+
+	if wasEmpty && len(cv.shownCommits) > 0 {
+		// the table skips past the rows shown while loading,
+		// so move the selection back to the first commit
+		cv.selectShown(0)
+	}
+}
+
+// extendGraph adds the rows of commits to the graph, which is drawn over the listed commits only
+func (cv *commitListView) extendGraph(commits []*object.Commit) {
+	if cv.isLimited() {
+		return
 	}
 
-	var noMergeCommits []*object.Commit
 	for _, commit := range commits {
-		if commit.NumParents() > 1 {
-			// ignore merges
-			continue
+		if cv.isShown(commit) {
+			cv.graphRows[commit.Hash] = cv.graph.Next(commit)
+		} else {
+			cv.graph.Skip(commit)
 		}
+	}
+}
+
+// rebuildGraph draws the graph again over the loaded commits
+func (cv *commitListView) rebuildGraph() {
+	cv.graph = newCommitGraph()
+	cv.graphRows = make(map[plumbing.Hash]string)
+	cv.extendGraph(cv.commits)
+}
+
+// rebuildRows recreates rows from the loaded commits, keeping the selection
+// on the same commit, or the next one shown if it is hidden
+func (cv *commitListView) rebuildRows() {
+	selected := cv.selectedCommit()
 
-		noMergeCommits = append(noMergeCommits, commit)
+	cv.clearRows()
+	cv.addRows(cv.sortedCommits())
+	if cv.hasMore {
+		cv.addLoadingRow()
 	}
 
+	if len(cv.shownCommits) == 0 {
+		cv.view.Select(0, 0)
+		return
+	}
+	if selected == nil {
+		cv.selectShown(0)
+		return
+	}
 
-	for idx, commit := range noMergeCommits {
+	// find the first shown commit at or after the selected one
+	shownIdx := 0
+	for _, commit := range cv.sortedCommits() {
+		if shownIdx < len(cv.shownCommits) && cv.shownCommits[shownIdx] == commit {
+			if commit == selected {
+				break
+			}
+			shownIdx++
+		} else if commit == selected {
+			break
+		}
+	}
+	if shownIdx >= len(cv.shownCommits) {
+		shownIdx = len(cv.shownCommits) - 1
+	}
 
-		tableView.SetCell(
-			idx+1, 0,
-			tview.NewTableCell(commit.Hash.String()[:10]))
-		
-		tableView.SetCell(
-			idx+1, 1,
-			tview.NewTableCell(commit.Message))
+	cv.view.Select(shownIdx+1, 0)
+	if cv.shownCommits[shownIdx] != selected && cv.top != nil {
+		cv.top.NotifyCommitSelectionChange(cv.shownCommits[shownIdx])
+	}
+}
 
-		idx++
+// clearRows removes all rows except the header
+func (cv *commitListView) clearRows() {
+	tableView := cv.view
+	for tableView.GetRowCount() > 1 {
+		tableView.RemoveRow(tableView.GetRowCount() - 1)
 	}
 
-	cv := commitListView{
-		top: top,
-		view: tableView,
-		commits: commits,
-		noMergeCommits: noMergeCommits,
+	cv.shownCommits = nil
+}
+
+// sortedCommits returns the loaded commits in the order they are listed
+func (cv *commitListView) sortedCommits() []*object.Commit {
+	if cv.sortColumn == "" {
+		return cv.commits
 	}
 
-	tableView.SetSelectionChangedFunc(cv.selectionChanged)
+	commits := make([]*object.Commit, len(cv.commits))
+	copy(commits, cv.commits)
+	sort.SliceStable(commits, func(i, j int) bool {
+		cmp := compareCommits(cv.sortColumn, commits[i], commits[j])
+		if cv.sortDescending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
 
-	return &cv
+	return commits
+}
 
+// addRows adds a row for each commit to be shown
+func (cv *commitListView) addRows(commits []*object.Commit) {
+	now := time.Now()
+	for _, commit := range commits {
+		if !cv.isShown(commit) {
+			continue
+		}
+
+		cv.shownCommits = append(cv.shownCommits, commit)
+		row := len(cv.shownCommits)
+
+		for col, column := range cv.columns {
+			cv.view.SetCell(row, col, cv.makeCell(column, commit, now))
+		}
+	}
 }
 
-func (cv *commitListView) GetView() *tview.Table {
-	return cv.view
+// makeCell creates the cell of the column for the commit
+func (cv *commitListView) makeCell(column CommitColumn, commit *object.Commit, now time.Time) *tview.TableCell {
+	switch column {
+	case ColumnGraph:
+		// the graph only makes sense in the log order,
+		// and with all the commits connecting the listed ones
+		if cv.sortColumn != "" || cv.isLimited() {
+			return tview.NewTableCell("")
+		}
+		return tview.NewTableCell(cv.graphRows[commit.Hash])
+	case ColumnHash:
+		return tview.NewTableCell(highlightPrefix(shortHash(commit), cv.search))
+	case ColumnRefs:
+		return tview.NewTableCell(formatDecorations(cv.decorations[commit.Hash]))
+	case columnMarks:
+		return tview.NewTableCell(formatMarks(cv.marks[commit.Hash]))
+	case ColumnMessage:
+		return tview.NewTableCell(highlightMatches(commitSubject(commit), cv.search))
+	case ColumnAuthor:
+		return tview.NewTableCell(highlightMatches(commit.Author.Name, cv.search))
+	case ColumnDate:
+		return tview.NewTableCell(formatDate(commit.Author.When, cv.relativeDate, now))
+	}
+
+	return tview.NewTableCell("")
+}
+
+// isLimited returns true if commits are left out of the history by paths or filters
+func (cv *commitListView) isLimited() bool {
+	return len(cv.paths) > 0 || cv.dropsCommits
+}
+
+// isShown returns true if the commit should have a row in the table
+func (cv *commitListView) isShown(commit *object.Commit) bool {
+	return cv.showMerges || commit.NumParents() <= 1
+}
+
+func (cv *commitListView) addLoadingRow() {
+	row := len(cv.shownCommits) + 1
+	// unset cells are selectable, so fill the whole row
+	for col := 0; col < cv.view.GetColumnCount(); col++ {
+		cv.view.SetCell(
+			row, col,
+			tview.NewTableCell("").SetSelectable(false))
+	}
+	text := cv.progress
+	if text == "" {
+		text = LoadingMoreText
+	}
+	cv.view.SetCell(
+		row, len(cv.columns) - 1,
+		tview.NewTableCell(tview.Escape(text)).
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+}
+
+func (cv *commitListView) removeLoadingRow() {
+	cv.view.RemoveRow(len(cv.shownCommits) + 1)
+}
+
+// selectedCommit returns the commit in the selected row, or nil
+func (cv *commitListView) selectedCommit() *object.Commit {
+	row, _ := cv.view.GetSelection()
+	idx := row - 1
+	if idx < 0 || idx >= len(cv.shownCommits) {
+		return nil
+	}
+	return cv.shownCommits[idx]
 }
 
 func (cv *commitListView) selectionChanged(row, column int) {
 	if cv.top != nil {
+		if len(cv.shownCommits) == 0 {
+			return
+		}
+
 		idx := row - 1
 		if idx < 0 {
 			idx = 0
-		} else if idx >= len(cv.noMergeCommits) {
-			idx = len(cv.noMergeCommits) - 1
+		} else if idx >= len(cv.shownCommits) {
+			idx = len(cv.shownCommits) - 1
 		}
 
-		commit := cv.noMergeCommits[idx]
+		commit := cv.shownCommits[idx]
 		cv.top.NotifyCommitSelectionChange(commit)
+
+		cv.loadMoreIfNeeded(idx)
+	}
+}
+
+// loadMoreIfNeeded requests more commits if idx is close to the last commit
+func (cv *commitListView) loadMoreIfNeeded(idx int) {
+	if cv.top != nil && cv.hasMore && len(cv.shownCommits) - idx <= LoadMoreThreshold {
+		cv.top.LoadMoreCommits()
 	}
 }
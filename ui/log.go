@@ -0,0 +1,509 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+)
+
+// RangeSeparator separates the excluded and the included revision of a range
+const RangeSeparator = ".."
+
+// SymmetricRangeSeparator separates the revisions of a symmetric difference,
+// which is not supported
+const SymmetricRangeSeparator = "..."
+
+// resolveCommit resolves a revision to a commit.
+// In addition to what repo.ResolveRevision supports, it accepts abbreviated hashes,
+// optionally followed by ancestry suffixes like ~2 or ^2.
+// Looking for an abbreviated hash stops early once ctx is done
+func resolveCommit(ctx context.Context, repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err == nil {
+		return repo.CommitObject(*hash)
+	}
+
+	prefix, suffix := rev, ""
+	if idx := strings.IndexAny(rev, "~^"); idx >= 0 {
+		prefix, suffix = rev[:idx], rev[idx:]
+	}
+	if !isHashPrefix(prefix) {
+		return nil, fmt.Errorf("unknown revision %s: %v", rev, err)
+	}
+
+	commit, err := findCommitByPrefix(ctx, repo, strings.ToLower(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	return walkAncestry(commit, suffix)
+}
+
+// walkAncestry returns the ancestor of commit the suffix of a revision leads to,
+// e.g. ~2 for the grandparent or ^2 for the second parent
+func walkAncestry(commit *object.Commit, suffix string) (*object.Commit, error) {
+	rest := suffix
+	for rest != "" {
+		op := rest[0]
+		if op != '~' && op != '^' {
+			return nil, fmt.Errorf("invalid revision suffix %s", suffix)
+		}
+
+		end := 1
+		for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+			end++
+		}
+		n := 1
+		if end > 1 {
+			var err error
+			if n, err = strconv.Atoi(rest[1:end]); err != nil {
+				return nil, fmt.Errorf("invalid revision suffix %s", suffix)
+			}
+		}
+		rest = rest[end:]
+
+		// ~n follows first parents n times, and ^n takes the nth parent
+		var err error
+		if op == '~' {
+			for ; n > 0 && err == nil; n-- {
+				commit, err = commit.Parent(0)
+			}
+		} else if n > 0 {
+			commit, err = commit.Parent(n - 1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("no commit at %s: %v", suffix, err)
+		}
+	}
+
+	return commit, nil
+}
+
+// isHashPrefix returns true if rev looks like an abbreviated hash
+func isHashPrefix(rev string) bool {
+	if len(rev) < 4 || len(rev) > 40 {
+		return false
+	}
+
+	for _, c := range strings.ToLower(rev) {
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findCommitByPrefix scans all commits for the one whose hash starts with prefix
+func findCommitByPrefix(ctx context.Context, repo *git.Repository, prefix string) (*object.Commit, error) {
+	iter, err := repo.CommitObjects()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var found *object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(c.Hash.String(), prefix) {
+			return nil
+		}
+
+		if found != nil && found.Hash != c.Hash {
+			return fmt.Errorf("short hash %s is ambiguous", prefix)
+		}
+		found = c
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("unknown revision %s", prefix)
+	}
+
+	return found, nil
+}
+
+// shortRevision shortens full reference names, e.g. refs/heads/main to main
+func shortRevision(rev string) string {
+	if strings.HasPrefix(rev, "refs/") {
+		return plumbing.ReferenceName(rev).Short()
+	}
+	return rev
+}
+
+// cleanPaths normalizes pathspecs to slash separated paths relative to the root,
+// dropping the ones meaning the whole tree
+func cleanPaths(paths []string) []string {
+	var cleaned []string
+	for _, p := range paths {
+		p = path.Clean(filepath.ToSlash(p))
+		p = strings.TrimPrefix(p, "/")
+		if p == "." || p == "" {
+			continue
+		}
+
+		cleaned = append(cleaned, p)
+	}
+
+	return cleaned
+}
+
+// openLog resolves rev and returns the commit history starts from
+// along with an iterator over the history.
+// rev can be a range A..B to list commits reachable from B but not from A.
+// An empty side of a range means HEAD
+func openLog(ctx context.Context, repo *git.Repository, rev string) (*object.Commit, object.CommitIter, error) {
+	if rev == "" {
+		rev = string(plumbing.HEAD)
+	}
+
+	if strings.Contains(rev, SymmetricRangeSeparator) {
+		return nil, nil, fmt.Errorf("symmetric difference %s is not supported, use A..B instead", rev)
+	}
+
+	var exclude *object.Commit
+	if idx := strings.Index(rev, RangeSeparator); idx >= 0 {
+		excludeRev := rev[:idx]
+		rev = rev[idx+len(RangeSeparator):]
+
+		if excludeRev == "" {
+			excludeRev = string(plumbing.HEAD)
+		}
+		if rev == "" {
+			rev = string(plumbing.HEAD)
+		}
+
+		var err error
+		exclude, err = resolveCommit(ctx, repo, excludeRev)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	head, err := resolveCommit(ctx, repo, rev)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if exclude != nil {
+		return head, newRangeCommitIter(head, exclude), nil
+	}
+
+	iter, err := repo.Log(&git.LogOptions{
+		From: head.Hash,
+		Order: git.LogOrderCommitterTime,
+	})
+
+	return head, iter, err
+}
+
+////////////////////////////////////////////////////////////
+// rangeCommitIter
+////////////////////////////////////////////////////////////
+
+// RangeSlop is the number of excluded commits walked on once only excluded commits are left,
+// in case commits have been made with a clock behind, as git does
+const RangeSlop = 5
+
+// rangeCommitIter lists commits reachable from a commit
+// excluding the ones reachable from another commit.
+// Like git, both sides are walked together newest first, passing the exclusion
+// down to parents, and the walk ends once only excluded commits are left,
+// so the history of the excluded side is only walked down to where the sides meet.
+// Commits are listed as they are walked, so a commit made with a clock ahead
+// of the excluded side may be listed before the walk finds it excluded
+type rangeCommitIter struct {
+	head *object.Commit
+	exclude *object.Commit
+
+	started bool
+	done bool
+	queue commitQueue
+	seen map[plumbing.Hash]bool
+	// walked holds the commits popped, whose parents have been queued
+	walked map[plumbing.Hash]*object.Commit
+	excluded map[plumbing.Hash]bool
+	slop int
+}
+
+func newRangeCommitIter(head, exclude *object.Commit) object.CommitIter {
+	return &rangeCommitIter{
+		head: head,
+		exclude: exclude,
+		seen: make(map[plumbing.Hash]bool),
+		walked: make(map[plumbing.Hash]*object.Commit),
+		excluded: make(map[plumbing.Hash]bool),
+	}
+}
+
+func (it *rangeCommitIter) Next() (*object.Commit, error) {
+	return it.NextContext(context.Background())
+}
+
+// NextContext walks on to the next commit reachable from head only.
+// Once ctx is done, it stops early and the next call walks on from where it stopped
+func (it *rangeCommitIter) NextContext(ctx context.Context) (*object.Commit, error) {
+	if !it.started {
+		it.started = true
+		it.excluded[it.exclude.Hash] = true
+		it.push(it.exclude)
+		it.push(it.head)
+		it.slop = RangeSlop
+	}
+
+	for !it.done && it.queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		c := heap.Pop(&it.queue).(queuedCommit).commit
+		it.walked[c.Hash] = c
+		excluded := it.excluded[c.Hash]
+
+		err := c.Parents().ForEach(func(parent *object.Commit) error {
+			if excluded {
+				it.excludeWalked(parent.Hash)
+			}
+			it.push(parent)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !excluded {
+			return c, nil
+		}
+
+		if it.queue.onlyExcluded(it.excluded) {
+			if it.slop--; it.slop == 0 {
+				it.done = true
+			}
+		} else {
+			it.slop = RangeSlop
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// push queues c unless it has been queued already
+func (it *rangeCommitIter) push(c *object.Commit) {
+	if !it.seen[c.Hash] {
+		it.seen[c.Hash] = true
+		heap.Push(&it.queue, queuedCommit{commit: c, order: len(it.seen)})
+	}
+}
+
+// excludeWalked excludes the commit, passing the exclusion down to
+// the walked commits below it, which happens when committer times are not in order
+func (it *rangeCommitIter) excludeWalked(hash plumbing.Hash) {
+	stack := []plumbing.Hash{hash}
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if it.excluded[h] {
+			continue
+		}
+
+		it.excluded[h] = true
+		if c, ok := it.walked[h]; ok {
+			stack = append(stack, c.ParentHashes...)
+		}
+	}
+}
+
+func (it *rangeCommitIter) ForEach(cb func(*object.Commit) error) error {
+	for {
+		c, err := it.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if err := cb(c); err != nil {
+			if err == storer.ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (it *rangeCommitIter) Close() {
+	it.done = true
+	it.queue = nil
+	it.walked = nil
+}
+
+// queuedCommit is a commit in commitQueue, where order is the order it has been queued in
+type queuedCommit struct {
+	commit *object.Commit
+	order int
+}
+
+// commitQueue is a heap of commits, the newest by committer time first,
+// and the first queued among commits as old
+type commitQueue []queuedCommit
+
+func (q commitQueue) Len() int { return len(q) }
+
+func (q commitQueue) Less(i, j int) bool {
+	a, b := q[i].commit.Committer.When, q[j].commit.Committer.When
+	if a.Equal(b) {
+		return q[i].order < q[j].order
+	}
+	return a.After(b)
+}
+
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *commitQueue) Push(x interface{}) {
+	*q = append(*q, x.(queuedCommit))
+}
+
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// onlyExcluded returns true if all the queued commits are excluded
+func (q commitQueue) onlyExcluded(excluded map[plumbing.Hash]bool) bool {
+	for _, c := range q {
+		if !excluded[c.commit.Hash] {
+			return false
+		}
+	}
+	return true
+}
+
+////////////////////////////////////////////////////////////
+// path filter
+////////////////////////////////////////////////////////////
+
+// pathFilter returns a filter accepting the commits changing any of paths.
+// Like git log, a merge is accepted only if it differs from all of its parents
+func pathFilter(paths []string) CommitFilter {
+	return func(ctx context.Context, c *object.Commit) (bool, error) {
+		tree, err := c.Tree()
+		if err != nil {
+			return false, err
+		}
+		hashes := pathHashes(tree, paths)
+
+		if c.NumParents() == 0 {
+			for _, h := range hashes {
+				if !h.IsZero() {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+
+		for i := 0; i < c.NumParents(); i++ {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+
+			parent, err := c.Parent(i)
+			if err != nil {
+				return false, err
+			}
+
+			parentTree, err := parent.Tree()
+			if err != nil {
+				return false, err
+			}
+
+			same := true
+			for idx, h := range pathHashes(parentTree, paths) {
+				if h != hashes[idx] {
+					same = false
+					break
+				}
+			}
+
+			if same {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+}
+
+// underPaths returns true if name is any of paths or in one of them,
+// or if paths is empty
+func underPaths(name string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	if name == "" {
+		return false
+	}
+
+	for _, p := range paths {
+		if name == p || strings.HasPrefix(name, p + "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// pathHashes returns the hash of the entry at each path in tree,
+// or a zero hash if there is none
+func pathHashes(tree *object.Tree, paths []string) []plumbing.Hash {
+	hashes := make([]plumbing.Hash, len(paths))
+	for idx, p := range paths {
+		if entry, err := tree.FindEntry(p); err == nil {
+			hashes[idx] = entry.Hash
+		}
+	}
+
+	return hashes
+}
@@ -0,0 +1,186 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// FilterDateFormat is the format dates of filters are shown in,
+// down to the second so that they are parsed back to the same date
+const FilterDateFormat = "2006-01-02 15:04:05"
+
+// FilterDateFormats are the formats accepted for absolute dates of filters
+var FilterDateFormats = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	FilterDateFormat,
+	time.RFC3339,
+}
+
+// LogFilter limits the history to commits by an author or in a date range.
+// Zero values do not limit the history
+type LogFilter struct {
+	// Author is a regular expression matched against "name <email>" of the author
+	Author string
+	// Since and Until limit the committer date
+	Since time.Time
+	Until time.Time
+
+	author *regexp.Regexp
+}
+
+// ParseLogFilter creates a LogFilter from an author pattern and dates,
+// which can be absolute in FilterDateFormats or relative to now, e.g. 2 weeks ago
+func ParseLogFilter(author, since, until string) (LogFilter, error) {
+	var f LogFilter
+	var err error
+
+	now := time.Now()
+	if f.Since, err = parseFilterDate(since, now); err != nil {
+		return f, fmt.Errorf("invalid since date: %v", err)
+	}
+	if f.Until, err = parseFilterDate(until, now); err != nil {
+		return f, fmt.Errorf("invalid until date: %v", err)
+	}
+
+	f.Author = author
+	if author != "" {
+		if f.author, err = regexp.Compile(author); err != nil {
+			return f, fmt.Errorf("invalid author: %v", err)
+		}
+	}
+
+	return f, nil
+}
+
+// parseFilterDate parses an absolute date, or a date relative to now
+func parseFilterDate(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, format := range FilterDateFormats {
+		if t, err := time.ParseInLocation(format, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// relative dates look like "3 days ago"
+	fields := strings.Fields(s)
+	if len(fields) == 3 && fields[2] == "ago" {
+		n, err := strconv.Atoi(fields[0])
+		if err == nil {
+			unit := strings.TrimSuffix(fields[1], "s")
+			switch unit {
+			case "minute":
+				return now.Add(-time.Duration(n) * time.Minute), nil
+			case "hour":
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case "day":
+				return now.AddDate(0, 0, -n), nil
+			case "week":
+				return now.AddDate(0, 0, -7*n), nil
+			case "month":
+				return now.AddDate(0, -n, 0), nil
+			case "year":
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown date %q", s)
+}
+
+// formatFilterDate formats t so that it can be parsed back, or empty if t is zero
+func formatFilterDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(FilterDateFormat)
+}
+
+// IsEmpty returns true if the filter does not limit the history
+func (f LogFilter) IsEmpty() bool {
+	return f.Author == "" && f.Since.IsZero() && f.Until.IsZero()
+}
+
+// dropsCommits returns true if the filter leaves out commits in the middle of the history.
+// Since and Until only trim its ends
+func (f LogFilter) dropsCommits() bool {
+	return f.Author != ""
+}
+
+// Descriptions describes each part of the filter as a git log option
+func (f LogFilter) Descriptions() []string {
+	var descriptions []string
+	if f.Author != "" {
+		descriptions = append(descriptions, fmt.Sprintf("--author=%s", f.Author))
+	}
+	if !f.Since.IsZero() {
+		descriptions = append(descriptions, fmt.Sprintf("--since=%s", formatFilterDate(f.Since)))
+	}
+	if !f.Until.IsZero() {
+		descriptions = append(descriptions, fmt.Sprintf("--until=%s", formatFilterDate(f.Until)))
+	}
+
+	return descriptions
+}
+
+// SinceSlop is the number of commits in a row older than Since after which
+// the walk stops, in case commits have been made with a clock behind, as git does
+const SinceSlop = 5
+
+// older returns true if the commit was made before Since
+func (f LogFilter) older(commit *object.Commit) bool {
+	return !f.Since.IsZero() && commit.Committer.When.Before(f.Since)
+}
+
+// matches returns true if the commit passes the filter
+func (f LogFilter) matches(commit *object.Commit) bool {
+	if f.author != nil {
+		signature := fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)
+		if !f.author.MatchString(signature) {
+			return false
+		}
+	}
+
+	when := commit.Committer.When
+	if !f.Since.IsZero() && when.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && when.After(f.Until) {
+		return false
+	}
+
+	return true
+}
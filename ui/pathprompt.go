@@ -0,0 +1,85 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// PathPromptView is a popup to enter the paths the history is limited to
+type PathPromptView interface {
+	GetView() *tview.InputField
+
+	// SetPaths fills the prompt with the paths currently used
+	SetPaths(paths []string)
+}
+
+type pathPromptView struct {
+	top TopLevelView
+
+	view *tview.InputField
+}
+
+////////////////////////////////////////////////////////////
+// pathPromptView functions
+////////////////////////////////////////////////////////////
+
+// NewPathPromptView creates an instance of PathPromptView
+func NewPathPromptView(top TopLevelView) PathPromptView {
+	inputField := tview.NewInputField().
+		SetLabel("Paths: ")
+
+	inputField.
+		SetBorder(true).
+		SetTitle("Limit history to paths (empty for all)")
+
+	pv := &pathPromptView{
+		top: top,
+		view: inputField,
+	}
+
+	inputField.SetDoneFunc(pv.done)
+
+	return pv
+}
+
+func (pv *pathPromptView) GetView() *tview.InputField {
+	return pv.view
+}
+
+func (pv *pathPromptView) SetPaths(paths []string) {
+	pv.view.SetText(strings.Join(paths, " "))
+}
+
+func (pv *pathPromptView) done(key tcell.Key) {
+	pv.top.ClosePopup()
+
+	if key == tcell.KeyEnter {
+		pv.top.NotifyPathsChange(strings.Fields(pv.view.GetText()))
+	}
+}
@@ -0,0 +1,179 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// pickaxe finds commits adding or removing a string like git log -S,
+// or changing lines matching a regular expression like git log -G
+type pickaxe struct {
+	query string
+	// re is nil to look for query literally
+	re *regexp.Regexp
+}
+
+// newPickaxe creates a pickaxe looking for query, as a regular expression if regex is true
+func newPickaxe(query string, regex bool) (*pickaxe, error) {
+	p := &pickaxe{query: query}
+	if regex {
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, err
+		}
+		p.re = re
+	}
+
+	return p, nil
+}
+
+func (p *pickaxe) String() string {
+	if p.re != nil {
+		return fmt.Sprintf("-G %s", p.query)
+	}
+	return fmt.Sprintf("-S %s", p.query)
+}
+
+// matches returns true if the commit changes what the pickaxe looks for
+// in any of paths, or anywhere if paths is empty, compared to its first parent.
+// Merges never match
+func (p *pickaxe) matches(ctx context.Context, commit *object.Commit, paths []string) (bool, error) {
+	if commit.NumParents() > 1 {
+		return false, nil
+	}
+
+	changes, err := commitChanges(ctx, commit)
+	if err != nil {
+		return false, err
+	}
+
+	for _, change := range changes {
+		if !underPaths(change.From.Name, paths) && !underPaths(change.To.Name, paths) {
+			continue
+		}
+
+		var matched bool
+		if p.re != nil {
+			matched, err = p.changesLines(ctx, change)
+		} else {
+			matched, err = p.changesCount(change)
+		}
+
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+
+	return false, nil
+}
+
+// changesCount returns true if the number of occurrences of the query
+// differs between both sides of the change
+func (p *pickaxe) changesCount(change *object.Change) (bool, error) {
+	from, to, err := change.Files()
+	if err != nil {
+		return false, err
+	}
+
+	count := func(f *object.File) (int, error) {
+		if f == nil {
+			return 0, nil
+		}
+
+		contents, err := f.Contents()
+		if err != nil {
+			return 0, err
+		}
+		return strings.Count(contents, p.query), nil
+	}
+
+	fromCount, err := count(from)
+	if err != nil {
+		return false, err
+	}
+
+	toCount, err := count(to)
+	if err != nil {
+		return false, err
+	}
+
+	return fromCount != toCount, nil
+}
+
+// changesLines returns true if an added or removed line of the change
+// matches the regular expression
+func (p *pickaxe) changesLines(ctx context.Context, change *object.Change) (bool, error) {
+	patch, err := change.PatchContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, filePatch := range patch.FilePatches() {
+		for _, chunk := range filePatch.Chunks() {
+			if chunk.Type() == diff.Equal {
+				continue
+			}
+
+			for _, line := range splitLines(chunk.Content()) {
+				if p.re.MatchString(line) {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// commitChanges computes the changes made by the commit against its first parent
+func commitChanges(ctx context.Context, commit *object.Commit) (object.Changes, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	// the first commit is compared against an empty tree
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return object.DiffTreeContext(ctx, parentTree, tree)
+}
@@ -0,0 +1,106 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// PickaxePromptTitle is the title of the prompt unless the regular expression is invalid
+const PickaxePromptTitle = "Find commits changing (empty for all)"
+
+// PickaxePromptView is a popup to enter the string or the regular expression
+// the history is searched for
+type PickaxePromptView interface {
+	GetView() *tview.InputField
+
+	// Open starts editing query, which is a regular expression if regex is true
+	Open(query string, regex bool)
+}
+
+type pickaxePromptView struct {
+	top TopLevelView
+
+	view *tview.InputField
+	regex bool
+}
+
+////////////////////////////////////////////////////////////
+// pickaxePromptView functions
+////////////////////////////////////////////////////////////
+
+// NewPickaxePromptView creates an instance of PickaxePromptView
+func NewPickaxePromptView(top TopLevelView) PickaxePromptView {
+	inputField := tview.NewInputField()
+
+	inputField.
+		SetBorder(true).
+		SetTitle(PickaxePromptTitle)
+
+	pv := &pickaxePromptView{
+		top: top,
+		view: inputField,
+	}
+
+	inputField.SetDoneFunc(pv.done)
+
+	return pv
+}
+
+func (pv *pickaxePromptView) GetView() *tview.InputField {
+	return pv.view
+}
+
+func (pv *pickaxePromptView) Open(query string, regex bool) {
+	pv.regex = regex
+	pv.view.SetTitle(PickaxePromptTitle)
+	if regex {
+		pv.view.SetLabel("-G ")
+	} else {
+		pv.view.SetLabel("-S ")
+	}
+	pv.view.SetText(query)
+}
+
+func (pv *pickaxePromptView) done(key tcell.Key) {
+	if key != tcell.KeyEnter {
+		pv.top.ClosePopup()
+		return
+	}
+
+	var p *pickaxe
+	if query := pv.view.GetText(); query != "" {
+		var err error
+		if p, err = newPickaxe(query, pv.regex); err != nil {
+			// keep the prompt open to fix the regular expression
+			pv.view.SetTitle(tview.Escape(err.Error()))
+			return
+		}
+	}
+
+	pv.top.ClosePopup()
+	pv.top.NotifyPickaxeChange(p)
+}
@@ -0,0 +1,114 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rivo/tview"
+
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+// RefPickerView is a popup to choose the revision the history starts from
+type RefPickerView interface {
+	GetView() *tview.List
+
+	// Reload refreshes the list of references
+	Reload()
+}
+
+type refPickerView struct {
+	top TopLevelView
+	worker Worker
+	repo *git.Repository
+
+	view *tview.List
+	refs []refEntry
+}
+
+////////////////////////////////////////////////////////////
+// refPickerView functions
+////////////////////////////////////////////////////////////
+
+// NewRefPickerView creates an instance of RefPickerView
+func NewRefPickerView(top TopLevelView, worker Worker, repo *git.Repository) RefPickerView {
+	listView := tview.NewList().
+		ShowSecondaryText(false)
+
+	listView.
+		SetBorder(true).
+		SetTitle("Select a ref")
+
+	rv := &refPickerView{
+		top: top,
+		worker: worker,
+		repo: repo,
+		view: listView,
+	}
+
+	listView.SetSelectedFunc(rv.refSelected)
+	listView.SetDoneFunc(top.ClosePopup)
+
+	return rv
+}
+
+func (rv *refPickerView) GetView() *tview.List {
+	return rv.view
+}
+
+func (rv *refPickerView) Reload() {
+	repo := rv.repo
+	rv.worker.Submit(JobRefPicker, func(ctx context.Context) func() {
+		refs, err := listRefs(repo)
+		if err != nil {
+			log.Printf("Failed to list refs: %v\n", err)
+		}
+
+		return func() {
+			rv.setRefs(refs)
+		}
+	})
+}
+
+func (rv *refPickerView) setRefs(refs []refEntry) {
+	rv.refs = refs
+	rv.view.Clear()
+	for _, r := range refs {
+		rv.view.AddItem(fmt.Sprintf("%-7s %s", r.kind, tview.Escape(r.name)), "", 0, nil)
+	}
+}
+
+func (rv *refPickerView) refSelected(index int, mainText, secondaryText string, shortcut rune) {
+	if index < 0 || index >= len(rv.refs) {
+		return
+	}
+
+	rv.top.ClosePopup()
+	rv.top.NotifyRevisionChange(rv.refs[index].ref.Name().String())
+}
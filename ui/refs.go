@@ -0,0 +1,140 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"sort"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// RefKind is the kind of a reference
+type RefKind int8
+
+const (
+	// RefKindHead is HEAD
+	RefKindHead RefKind = iota
+	// RefKindBranch is a local branch
+	RefKindBranch
+	// RefKindRemote is a remote-tracking branch
+	RefKindRemote
+	// RefKindTag is a tag
+	RefKindTag
+)
+
+func (k RefKind) String() string {
+	switch k {
+	case RefKindHead:
+		return "head"
+	case RefKindBranch:
+		return "branch"
+	case RefKindRemote:
+		return "remote"
+	case RefKindTag:
+		return "tag"
+	}
+	return ""
+}
+
+// refEntry is a reference that can be used as a revision
+type refEntry struct {
+	kind RefKind
+	// name is the short name of the reference, e.g. main or origin/main
+	name string
+	ref *plumbing.Reference
+}
+
+// listRefs returns HEAD followed by branches, remote-tracking branches and tags,
+// each group sorted by name
+func listRefs(repo *git.Repository) ([]refEntry, error) {
+	var entries []refEntry
+
+	if head, err := repo.Head(); err == nil {
+		entries = append(entries, refEntry{
+			kind: RefKindHead,
+			name: string(plumbing.HEAD),
+			ref: plumbing.NewHashReference(plumbing.HEAD, head.Hash()),
+		})
+	}
+
+	iter, err := repo.References()
+	if err != nil {
+		return entries, err
+	}
+	defer iter.Close()
+
+	var refs []refEntry
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		// symbolic refs such as origin/HEAD point to refs listed anyway
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		name := ref.Name()
+		switch {
+		case name.IsBranch():
+			refs = append(refs, refEntry{kind: RefKindBranch, name: name.Short(), ref: ref})
+		case name.IsRemote():
+			refs = append(refs, refEntry{kind: RefKindRemote, name: name.Short(), ref: ref})
+		case name.IsTag():
+			refs = append(refs, refEntry{kind: RefKindTag, name: name.Short(), ref: ref})
+		}
+
+		return nil
+	})
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].kind != refs[j].kind {
+			return refs[i].kind < refs[j].kind
+		}
+		return refs[i].name < refs[j].name
+	})
+
+	return append(entries, refs...), err
+}
+
+// refDecorations returns the refs pointing to each commit,
+// annotated tags being peeled to the commit they point to
+func refDecorations(repo *git.Repository) (map[plumbing.Hash][]refEntry, error) {
+	entries, err := listRefs(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	decorations := make(map[plumbing.Hash][]refEntry)
+	for _, entry := range entries {
+		hash := entry.ref.Hash()
+		if entry.kind == RefKindTag {
+			if tag, err := repo.TagObject(hash); err == nil {
+				hash = tag.Target
+			}
+		}
+
+		decorations[hash] = append(decorations[hash], entry)
+	}
+
+	return decorations, nil
+}
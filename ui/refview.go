@@ -0,0 +1,192 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+// RefListView is a view to list branches, remote-tracking branches and tags
+type RefListView interface {
+	GetView() *tview.Table
+
+	// Reload refreshes the list of references in the background
+	Reload()
+}
+
+type refListView struct {
+	top TopLevelView
+	worker Worker
+	repo *git.Repository
+
+	view *tview.Table
+	// refs holds the reference shown in each row, nil for group headers
+	refs []*refEntry
+}
+
+// RefGroupColor is the color of the rows separating kinds of references
+const RefGroupColor = tcell.ColorTeal
+
+// refGroupTitles are the titles of the rows separating kinds of references
+var refGroupTitles = map[RefKind]string{
+	RefKindBranch: "Branches",
+	RefKindRemote: "Remotes",
+	RefKindTag: "Tags",
+}
+
+////////////////////////////////////////////////////////////
+// refListView functions
+////////////////////////////////////////////////////////////
+
+// NewRefListView creates an instance of RefListView
+func NewRefListView(top TopLevelView, worker Worker, repo *git.Repository) RefListView {
+	tableView := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(
+			true,		// rows
+			false,		// columns
+		)
+	tableView.
+		SetBorder(true).
+		SetTitle("Refs")
+
+	rv := &refListView{
+		top: top,
+		worker: worker,
+		repo: repo,
+		view: tableView,
+	}
+
+	tableView.SetSelectedFunc(rv.refSelected)
+
+	return rv
+}
+
+func (rv *refListView) GetView() *tview.Table {
+	return rv.view
+}
+
+func (rv *refListView) Reload() {
+	repo := rv.repo
+	rv.worker.Submit(JobRefs, func(ctx context.Context) func() {
+		refs, err := listRefs(repo)
+		if err != nil {
+			log.Printf("Failed to list refs: %v\n", err)
+		}
+
+		messages := make([]string, len(refs))
+		for idx, r := range refs {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if r.kind == RefKindTag {
+				messages[idx] = tagMessage(repo, r)
+			}
+		}
+
+		return func() {
+			rv.setRefs(refs, messages)
+			if err != nil {
+				rv.view.SetTitle(fmt.Sprintf("Refs (failed: %s)", tview.Escape(err.Error())))
+			} else {
+				rv.view.SetTitle("Refs")
+			}
+		}
+	})
+}
+
+// tagMessage returns the subject of an annotated tag, or an empty string
+// for lightweight tags
+func tagMessage(repo *git.Repository, r refEntry) string {
+	tag, err := repo.TagObject(r.ref.Hash())
+	if err != nil {
+		return ""
+	}
+
+	return messageSubject(tag.Message)
+}
+
+func (rv *refListView) setRefs(refs []refEntry, messages []string) {
+	tableView := rv.view
+	tableView.Clear()
+	rv.refs = nil
+
+	row := 0
+	lastKind := RefKindHead
+	for idx := range refs {
+		r := &refs[idx]
+		if r.kind == RefKindHead {
+			// HEAD is always reachable with the ref picker
+			continue
+		}
+
+		if r.kind != lastKind {
+			tableView.SetCell(
+				row, 0,
+				TableFormatting.Header(
+					tview.NewTableCell(refGroupTitles[r.kind]).
+						SetSelectable(false).
+						SetTextColor(RefGroupColor)).
+					SetAlign(tview.AlignLeft))
+			// unset cells are selectable, so fill the rest of the row
+			tableView.SetCell(
+				row, 1,
+				tview.NewTableCell("").SetSelectable(false))
+			rv.refs = append(rv.refs, nil)
+			row++
+			lastKind = r.kind
+		}
+
+		tableView.SetCell(
+			row, 0,
+			tview.NewTableCell(" " + tview.Escape(r.name)))
+		tableView.SetCell(
+			row, 1,
+			tview.NewTableCell(tview.Escape(messages[idx])).
+				SetTextColor(tcell.ColorGray).
+				SetExpansion(1))
+		rv.refs = append(rv.refs, r)
+		row++
+	}
+
+	tableView.ScrollToBeginning()
+}
+
+func (rv *refListView) refSelected(row, column int) {
+	if row < 0 || row >= len(rv.refs) || rv.refs[row] == nil {
+		return
+	}
+
+	rv.top.NotifyRevisionChange(rv.refs[row].ref.Name().String())
+}
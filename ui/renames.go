@@ -0,0 +1,269 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+)
+
+// DefaultRenameThreshold is the similarity in percent from which
+// a deleted or modified file and an added one are a rename or a copy, as git does
+const DefaultRenameThreshold = 50
+
+// RenameArrow separates the old path of a renamed or copied file from the new one
+const RenameArrow = " → "
+
+// MaxRenamePairs is the number of pairs of files above which
+// only renames and copies without changes are detected
+const MaxRenamePairs = 10000
+
+// MaxRenameFileSize is the size of files above which
+// only renames and copies without changes are detected
+const MaxRenameFileSize = 1024 * 1024
+
+// renameInfo describes a change detected as a rename or a copy
+type renameInfo struct {
+	copied bool
+	// similarity is in percent
+	similarity int
+}
+
+// renameSource is a file an added file may have been renamed or copied from
+type renameSource struct {
+	change *object.Change
+	// copied is true if the file is kept, i.e. modified
+	copied bool
+}
+
+// renamePair is a source and an added file similar enough to be a rename or a copy
+type renamePair struct {
+	source int
+	added int
+	similarity int
+}
+
+// detectRenames replaces added files similar to deleted ones by renames,
+// and to modified ones by copies, as git does with -M and -C.
+// It returns the changes, and how the renames and copies are detected.
+// Nothing is detected if threshold is not positive
+func detectRenames(ctx context.Context, changes object.Changes, threshold int) (object.Changes, map[*object.Change]renameInfo, error) {
+	renames := make(map[*object.Change]renameInfo)
+	if threshold <= 0 {
+		return changes, renames, nil
+	}
+
+	var sources []renameSource
+	var added []*object.Change
+	for _, c := range changes {
+		action, err := c.Action()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch action {
+		case merkletrie.Delete:
+			sources = append(sources, renameSource{change: c})
+		case merkletrie.Modify:
+			sources = append(sources, renameSource{change: c, copied: true})
+		case merkletrie.Insert:
+			added = append(added, c)
+		}
+	}
+	if len(sources) == 0 || len(added) == 0 {
+		return changes, renames, nil
+	}
+
+	pairs, err := similarPairs(ctx, sources, added, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the most similar pairs are taken first, and a deleted file is renamed once
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].similarity > pairs[j].similarity
+	})
+
+	replaced := make(map[*object.Change]*object.Change)
+	for _, p := range pairs {
+		source, target := sources[p.source], added[p.added]
+		if _, done := replaced[target]; done {
+			continue
+		}
+		// a deleted file already renamed is copied to the other files
+		_, renamed := replaced[source.change]
+		copied := source.copied || renamed
+
+		c := &object.Change{From: source.change.From, To: target.To}
+		replaced[target] = c
+		if !copied {
+			// the deleted file is part of the rename
+			replaced[source.change] = nil
+		}
+		renames[c] = renameInfo{copied: copied, similarity: p.similarity}
+	}
+
+	var result object.Changes
+	for _, c := range changes {
+		r, ok := replaced[c]
+		switch {
+		case !ok:
+			result = append(result, c)
+		case r != nil:
+			result = append(result, r)
+		}
+	}
+
+	return result, renames, nil
+}
+
+// similarPairs returns the pairs of sources and added files
+// whose similarity is at least threshold
+func similarPairs(ctx context.Context, sources []renameSource, added []*object.Change, threshold int) ([]renamePair, error) {
+	// comparing contents is only affordable for a limited number of files
+	compareContents := len(sources) * len(added) <= MaxRenamePairs
+
+	signatures := make(map[plumbing.Hash]*fileSignature)
+	signature := func(entry object.ChangeEntry) (*fileSignature, error) {
+		hash := entry.TreeEntry.Hash
+		if s, ok := signatures[hash]; ok {
+			return s, nil
+		}
+
+		s, err := newFileSignature(entry)
+		if err != nil {
+			return nil, err
+		}
+		signatures[hash] = s
+		return s, nil
+	}
+
+	var pairs []renamePair
+	for i, source := range sources {
+		for j, a := range added {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			from, to := source.change.From.TreeEntry, a.To.TreeEntry
+			if from.Hash == to.Hash {
+				pairs = append(pairs, renamePair{source: i, added: j, similarity: 100})
+				continue
+			}
+			if !compareContents {
+				continue
+			}
+
+			fromSig, err := signature(source.change.From)
+			if err != nil {
+				return nil, err
+			}
+			toSig, err := signature(a.To)
+			if err != nil {
+				return nil, err
+			}
+
+			if similarity := fromSig.similarity(toSig); similarity >= threshold {
+				pairs = append(pairs, renamePair{source: i, added: j, similarity: similarity})
+			}
+		}
+	}
+
+	return pairs, nil
+}
+
+// fileSignature summarizes the content of a file to compare it with others
+type fileSignature struct {
+	size int
+	// lines counts the occurrences of each line
+	lines map[string]int
+}
+
+// newFileSignature reads the file of entry.
+// Binary, empty and large files have no lines, so are only similar when identical
+func newFileSignature(entry object.ChangeEntry) (*fileSignature, error) {
+	file, err := entry.Tree.TreeEntryFile(&entry.TreeEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &fileSignature{size: int(file.Size)}
+	if file.Size == 0 || file.Size > MaxRenameFileSize {
+		return s, nil
+	}
+	if binary, err := file.IsBinary(); err != nil || binary {
+		return s, err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(reader, MaxRenameFileSize))
+	if err != nil {
+		return nil, err
+	}
+
+	s.lines = make(map[string]int)
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if len(line) > 0 {
+			s.lines[string(line)]++
+		}
+	}
+
+	return s, nil
+}
+
+// similarity returns the percentage of the bytes of the larger file
+// in lines common to both files
+func (s *fileSignature) similarity(other *fileSignature) int {
+	if s.lines == nil || other.lines == nil {
+		return 0
+	}
+
+	common := 0
+	for line, count := range s.lines {
+		if otherCount := other.lines[line]; otherCount < count {
+			common += otherCount * len(line)
+		} else {
+			common += count * len(line)
+		}
+	}
+
+	size := s.size
+	if other.size > size {
+		size = other.size
+	}
+	return common * 100 / size
+}
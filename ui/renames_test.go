@@ -0,0 +1,200 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// testRepository is a repository in memory whose commits are made of whole file sets
+type testRepository struct {
+	t *testing.T
+	repo *git.Repository
+	worktree *git.Worktree
+	files map[string]string
+}
+
+func newTestRepository(t *testing.T) *testRepository {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testRepository{t: t, repo: repo, worktree: worktree}
+}
+
+// commit commits files as the whole content of the repository
+func (tr *testRepository) commit(files map[string]string) *object.Commit {
+	for name := range tr.files {
+		if _, ok := files[name]; !ok {
+			if _, err := tr.worktree.Remove(name); err != nil {
+				tr.t.Fatal(err)
+			}
+		}
+	}
+	for name, content := range files {
+		if err := util.WriteFile(tr.worktree.Filesystem, name, []byte(content), 0644); err != nil {
+			tr.t.Fatal(err)
+		}
+		if _, err := tr.worktree.Add(name); err != nil {
+			tr.t.Fatal(err)
+		}
+	}
+	tr.files = files
+
+	hash, err := tr.worktree.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		tr.t.Fatal(err)
+	}
+	commit, err := tr.repo.CommitObject(hash)
+	if err != nil {
+		tr.t.Fatal(err)
+	}
+	return commit
+}
+
+// testLines returns n distinct lines, so that files made of them are similar
+func testLines(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteString(strings.Repeat("x", i+1))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// treeNodes maps the paths of the nodes under node to their nodes
+func treeNodes(node *tview.TreeNode, nodes map[string]*tview.TreeNode) map[string]*tview.TreeNode {
+	dir := node.GetReference().(*treeNodeData).entry.Name
+	for _, child := range node.GetChildren() {
+		entry := child.GetReference().(*treeNodeData).entry
+		// directories are named by their paths, and files by their names
+		if entry.Mode == filemode.Dir {
+			nodes[entry.Name] = child
+			treeNodes(child, nodes)
+		} else {
+			nodes[path.Join(dir, entry.Name)] = child
+		}
+	}
+	return nodes
+}
+
+func TestBuildTreeRenames(t *testing.T) {
+	content := testLines(20)
+
+	tests := []struct {
+		name string
+		from string
+		to string
+		edit string
+		copied bool
+	}{
+		{name: "same directory", from: "a/x.txt", to: "a/y.txt"},
+		{name: "up", from: "a/b/x.txt", to: "y.txt"},
+		{name: "down", from: "y.txt", to: "a/b/x.txt"},
+		{name: "across", from: "a/b/x.txt", to: "c/d/x.txt"},
+		{name: "edited", from: "a/b/x.txt", to: "c/x.txt", edit: "edited\n"},
+		{name: "copied", from: "a/x.txt", to: "b/x.txt", copied: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tr := newTestRepository(t)
+			parent := tr.commit(map[string]string{test.from: content, "other.txt": "other\n"})
+
+			files := map[string]string{test.to: content + test.edit, "other.txt": "other\n"}
+			if test.copied {
+				// copies are detected from modified files
+				files[test.from] = content + "modified\n"
+			}
+			commit := tr.commit(files)
+
+			refTree, err := parent.Tree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree, err := commit.Tree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			changes, err := object.DiffTree(refTree, tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changes, renames, err := detectRenames(context.Background(), changes, DefaultRenameThreshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(renames) != 1 {
+				t.Fatalf("detected %d renames, want 1", len(renames))
+			}
+
+			for _, changedOnly := range []bool{false, true} {
+				root := buildTree(".", []string{}, tree, refTree, changes, renames, MaxOpenDepth, changedOnly)
+				nodes := treeNodes(root, make(map[string]*tview.TreeNode))
+
+				node, ok := nodes[test.to]
+				if !ok {
+					t.Fatalf("changedOnly=%v: no node for %s", changedOnly, test.to)
+				}
+				data := node.GetReference().(*treeNodeData)
+				if data.rename == nil || data.rename.copied != test.copied {
+					t.Errorf("changedOnly=%v: rename of %s is %v", changedOnly, test.to, data.rename)
+				}
+				if from := data.changes[0].From.Name; from != test.from {
+					t.Errorf("changedOnly=%v: %s renamed from %s, want %s", changedOnly, test.to, from, test.from)
+				}
+
+				// the old path is only shown when the file is copied
+				if _, ok := nodes[test.from]; ok != test.copied {
+					t.Errorf("changedOnly=%v: node for %s shown: %v", changedOnly, test.from, ok)
+				}
+				if _, ok := nodes["other.txt"]; ok == changedOnly {
+					t.Errorf("changedOnly=%v: unchanged other.txt shown: %v", changedOnly, ok)
+				}
+			}
+		})
+	}
+}
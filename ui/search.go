@@ -0,0 +1,140 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// Colors of text matching the search
+const (
+	SearchMatchColor = tcell.ColorBlack
+	SearchMatchBackgroundColor = tcell.ColorYellow
+)
+
+// Searchable is a view that can be searched with the search prompt
+type Searchable interface {
+	// SetSearch highlights the matches of query, and moves to the first one
+	// at or after the current position. An empty query clears the search
+	SetSearch(query string)
+
+	// NextMatch moves to the next match, or the previous one if forward is false
+	NextMatch(forward bool)
+
+	// GetSearch returns the query being searched
+	GetSearch() string
+}
+
+// highlightMatches escapes text, and highlights case insensitive matches of query in it
+func highlightMatches(text, query string) string {
+	if query == "" {
+		return tview.Escape(text)
+	}
+
+	lowerText := strings.ToLower(text)
+	lowerQuery := strings.ToLower(query)
+	// lowering may change the length of some characters
+	if len(lowerText) != len(text) {
+		return tview.Escape(text)
+	}
+
+	var b strings.Builder
+	for {
+		idx := strings.Index(lowerText, lowerQuery)
+		if idx < 0 {
+			break
+		}
+
+		end := idx + len(lowerQuery)
+		fmt.Fprintf(&b, "%s[#%06x:#%06x]%s[-:-]",
+			tview.Escape(text[:idx]),
+			SearchMatchColor.Hex(), SearchMatchBackgroundColor.Hex(),
+			tview.Escape(text[idx:end]))
+
+		text = text[end:]
+		lowerText = lowerText[end:]
+	}
+	b.WriteString(tview.Escape(text))
+
+	return b.String()
+}
+
+// highlightPrefix escapes text, and highlights query if text starts with it
+func highlightPrefix(text, query string) string {
+	if query == "" || !strings.HasPrefix(strings.ToLower(text), strings.ToLower(query)) {
+		return tview.Escape(text)
+	}
+
+	return fmt.Sprintf("[#%06x:#%06x]%s[-:-]%s",
+		SearchMatchColor.Hex(), SearchMatchBackgroundColor.Hex(),
+		tview.Escape(text[:len(query)]), tview.Escape(text[len(query):]))
+}
+
+// textMatches returns true if highlightMatches highlights query in text
+func textMatches(text, query string) bool {
+	lowerText := strings.ToLower(text)
+	return query != "" && len(lowerText) == len(text) &&
+		strings.Contains(lowerText, strings.ToLower(query))
+}
+
+// prefixMatches returns true if highlightPrefix highlights query in text
+func prefixMatches(text, query string) bool {
+	return query != "" && strings.HasPrefix(strings.ToLower(text), strings.ToLower(query))
+}
+
+// indexFold returns the byte offsets in text where the first case insensitive
+// match of query starts and ends, or -1, -1 if there is none.
+// Characters are compared one by one, since lowering may change their length
+func indexFold(text, query string) (int, int) {
+	for start := range text {
+		if end, ok := prefixFold(text[start:], query); ok {
+			return start, start + end
+		}
+	}
+	return -1, -1
+}
+
+// prefixFold returns the length in text of query if text starts with it ignoring case
+func prefixFold(text, query string) (int, bool) {
+	n := 0
+	for _, q := range query {
+		if n >= len(text) {
+			return 0, false
+		}
+
+		r, size := utf8.DecodeRuneInString(text[n:])
+		if r != q && unicode.ToLower(r) != unicode.ToLower(q) {
+			return 0, false
+		}
+		n += size
+	}
+	return n, true
+}
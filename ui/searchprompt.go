@@ -0,0 +1,95 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// SearchPromptView is a popup to search a view as the query is typed
+type SearchPromptView interface {
+	GetView() *tview.InputField
+
+	// Open starts searching target, beginning with query
+	Open(target Searchable, query string)
+}
+
+type searchPromptView struct {
+	top TopLevelView
+
+	view *tview.InputField
+	target Searchable
+}
+
+////////////////////////////////////////////////////////////
+// searchPromptView functions
+////////////////////////////////////////////////////////////
+
+// NewSearchPromptView creates an instance of SearchPromptView
+func NewSearchPromptView(top TopLevelView) SearchPromptView {
+	inputField := tview.NewInputField().
+		SetLabel("/")
+
+	inputField.
+		SetBorder(true).
+		SetTitle("Search (Enter to keep, Esc to clear)")
+
+	sv := &searchPromptView{
+		top: top,
+		view: inputField,
+	}
+
+	inputField.SetChangedFunc(sv.changed)
+	inputField.SetDoneFunc(sv.done)
+
+	return sv
+}
+
+func (sv *searchPromptView) GetView() *tview.InputField {
+	return sv.view
+}
+
+func (sv *searchPromptView) Open(target Searchable, query string) {
+	// set the text first not to search the previous target
+	sv.target = nil
+	sv.view.SetText(query)
+	sv.target = target
+}
+
+func (sv *searchPromptView) changed(text string) {
+	if sv.target != nil {
+		sv.target.SetSearch(text)
+	}
+}
+
+func (sv *searchPromptView) done(key tcell.Key) {
+	if key == tcell.KeyEscape && sv.target != nil {
+		sv.target.SetSearch("")
+	}
+
+	sv.target = nil
+	sv.top.ClosePopup()
+}
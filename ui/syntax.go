@@ -0,0 +1,137 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/gdamore/tcell"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+)
+
+// SyntaxStyle is the chroma style code is colored with
+const SyntaxStyle = "monokai"
+
+// syntaxSpan is a range of a line colored by its syntax
+type syntaxSpan struct {
+	textRange
+	color tcell.Color
+}
+
+// fileLexer returns the lexer of the language of the file at path, or nil if unknown
+func fileLexer(path string) chroma.Lexer {
+	lexer := lexers.Match(filepath.Base(path))
+	if lexer == nil {
+		return nil
+	}
+
+	return chroma.Coalesce(lexer)
+}
+
+// patchPath returns the path of the file changed by patch,
+// which is the old path of a deleted file
+func patchPath(patch diff.FilePatch) string {
+	from, to := patch.Files()
+	if to != nil {
+		return to.Path()
+	}
+	if from != nil {
+		return from.Path()
+	}
+	return ""
+}
+
+// markSyntax colors the lines with lexer.
+// The old and new files are lexed separately to keep the state spanning lines,
+// such as comments, and unchanged lines are colored as part of the new one
+func markSyntax(lines []diffLine, lexer chroma.Lexer) {
+	var oldLines, newLines []int
+	for idx, l := range lines {
+		if l.op != diff.Add {
+			oldLines = append(oldLines, idx)
+		}
+		if l.op != diff.Delete {
+			newLines = append(newLines, idx)
+		}
+	}
+
+	texts := func(indices []int) []string {
+		var result []string
+		for _, idx := range indices {
+			result = append(result, lines[idx].text)
+		}
+		return result
+	}
+
+	for i, spans := range lexLines(lexer, texts(oldLines)) {
+		if l := &lines[oldLines[i]]; l.op == diff.Delete {
+			l.syntax = spans
+		}
+	}
+	for i, spans := range lexLines(lexer, texts(newLines)) {
+		lines[newLines[i]].syntax = spans
+	}
+}
+
+// lexLines lexes the lines as a whole, and returns the colored spans of each line
+func lexLines(lexer chroma.Lexer, texts []string) [][]syntaxSpan {
+	spans := make([][]syntaxSpan, len(texts))
+
+	iter, err := lexer.Tokenise(nil, strings.Join(texts, "\n"))
+	if err != nil {
+		return spans
+	}
+
+	style := styles.Get(SyntaxStyle)
+	line, pos := 0, 0
+	for _, token := range iter.Tokens() {
+		colour := style.Get(token.Type).Colour
+		for idx, part := range strings.Split(token.Value, "\n") {
+			if idx > 0 {
+				line++
+				pos = 0
+			}
+			if line >= len(texts) {
+				// lexers may add a newline at the end
+				return spans
+			}
+
+			if part != "" && colour.IsSet() {
+				spans[line] = append(spans[line], syntaxSpan{
+					textRange: textRange{start: pos, end: pos + len(part)},
+					color: tcell.NewRGBColor(int32(colour.Red()), int32(colour.Green()), int32(colour.Blue())),
+				})
+			}
+			pos += len(part)
+		}
+	}
+
+	return spans
+}
@@ -25,8 +25,9 @@
 package ui
 
 import (
+	"github.com/rivo/tview"
+
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
-	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
 )
 
 // DiffMode determines which changes treeview shows
@@ -37,6 +38,8 @@ const (
 	DiffModeSingle DiffMode = iota
 	// DiffModeAcc shows diffs compared to the head
 	DiffModeAcc
+	// DiffModeCombined shows files of a merge commit that differ from all parents
+	DiffModeCombined
 )
 
 // TopLevelView is the top level container
@@ -47,6 +50,37 @@ type TopLevelView interface {
 	NotifyCommitSelectionChange(commit *object.Commit)
 
 	// NotifyFileSelectionChange is called to notify file selection has been changed
-	NotifyFileSelectionChange(patch diff.FilePatch)
-	
+	NotifyFileSelectionChange(text *textDiff)
+
+	// NotifyBinaryFileSelectionChange is called to notify a binary file has been selected
+	NotifyBinaryFileSelectionChange(binary *binaryDiff)
+
+	// LoadMoreCommits is called to request the next page of commits
+	LoadMoreCommits()
+
+	// SelectCommit selects commit in the commit list, loading history as far as needed.
+	// The history of commit is shown if it is not part of the current one
+	SelectCommit(commit *object.Commit)
+
+	// NotifyRevisionChange is called to show the history of another revision
+	NotifyRevisionChange(rev string)
+
+	// NotifyPathsChange is called to limit the history to paths
+	NotifyPathsChange(paths []string)
+
+	// NotifyPickaxeChange is called to list only commits changing what p looks for.
+	// A nil p lists all commits
+	NotifyPickaxeChange(p *pickaxe)
+
+	// NotifyFilterChange is called to limit the history by author and dates
+	NotifyFilterChange(filter LogFilter)
+
+	// NotifyBookmarksChange is called after bookmarks are added or removed
+	NotifyBookmarksChange()
+
+	// ClosePopup closes the popup currently shown
+	ClosePopup()
+
+	// SetFocus focuses p, e.g. a part of the popup currently shown
+	SetFocus(p tview.Primitive)
 }
@@ -0,0 +1,154 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/rivo/tview"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+)
+
+// fuzzyMatch returns the positions of the runes of text matching the runes of pattern
+// in order, ignoring case, or nil if text does not match.
+// Matches are looked for from the end, so that they fall in the file name when possible
+func fuzzyMatch(text, pattern string) []int {
+	t := []rune(text)
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return nil
+	}
+
+	positions := make([]int, len(p))
+	j := len(p) - 1
+	for i := len(t) - 1; i >= 0 && j >= 0; i-- {
+		if unicode.ToLower(t[i]) == unicode.ToLower(p[j]) {
+			positions[j] = i
+			j--
+		}
+	}
+
+	if j >= 0 {
+		return nil
+	}
+	return positions
+}
+
+// highlightRunes escapes text, and makes the runes at positions bold and underlined
+func highlightRunes(text string, positions map[int]bool) string {
+	var b strings.Builder
+	highlighted := false
+	for i, r := range []rune(text) {
+		if positions[i] != highlighted {
+			highlighted = positions[i]
+			if highlighted {
+				b.WriteString("[::bu]")
+			} else {
+				b.WriteString("[::-]")
+			}
+		}
+		b.WriteString(tview.Escape(string(r)))
+	}
+	if highlighted {
+		b.WriteString("[::-]")
+	}
+
+	return b.String()
+}
+
+// filterTree removes the files under root whose path does not fuzzy match pattern
+// along with the directories left empty, and expands the remaining directories.
+// It returns the files left in the order they are shown
+func filterTree(root *tview.TreeNode, pattern string) []*tview.TreeNode {
+	highlights := make(map[*tview.TreeNode]map[int]bool)
+	files := pruneTree(root, nil, pattern, highlights)
+
+	for node, positions := range highlights {
+		text := highlightRunes(treeNodeName(node), positions)
+		node.SetText(treeNodeText(text, node.GetReference().(*treeNodeData)))
+	}
+
+	return files
+}
+
+// pruneTree filters the children of node, and returns the files left under it.
+// ancestors are the directories above node, and the matched runes of each node
+// are collected into highlights
+func pruneTree(node *tview.TreeNode, ancestors []*tview.TreeNode, pattern string, highlights map[*tview.TreeNode]map[int]bool) []*tview.TreeNode {
+	dirPath := node.GetReference().(*treeNodeData).entry.Name
+	// the root has no name in paths
+	if dirPath != "" {
+		ancestors = append(ancestors, node)
+	}
+
+	var kept []*tview.TreeNode
+	var files []*tview.TreeNode
+	for _, child := range node.GetChildren() {
+		data := child.GetReference().(*treeNodeData)
+		if data.entry.Mode == filemode.Dir {
+			if childFiles := pruneTree(child, ancestors, pattern, highlights); len(childFiles) > 0 {
+				child.SetExpanded(true)
+				kept = append(kept, child)
+				files = append(files, childFiles...)
+			}
+			continue
+		}
+
+		positions := fuzzyMatch(path.Join(dirPath, data.entry.Name), pattern)
+		if positions == nil {
+			continue
+		}
+		kept = append(kept, child)
+		files = append(files, child)
+
+		// map the positions in the path to the nodes of its components
+		components := append(ancestors[:len(ancestors):len(ancestors)], child)
+		start := 0
+		for _, c := range components {
+			length := len([]rune(treeNodeName(c)))
+			for _, pos := range positions {
+				if pos >= start && pos < start+length {
+					if highlights[c] == nil {
+						highlights[c] = make(map[int]bool)
+					}
+					highlights[c][pos-start] = true
+				}
+			}
+			// skip the separator
+			start += length + 1
+		}
+	}
+
+	node.SetChildren(kept)
+	return files
+}
+
+// treeNodeName returns the name of the file or the directory of node
+func treeNodeName(node *tview.TreeNode) string {
+	return path.Base(node.GetReference().(*treeNodeData).entry.Name)
+}
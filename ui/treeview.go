@@ -25,6 +25,8 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"sort"
 	"strings"
 
@@ -46,13 +48,63 @@ type TreeContentView interface {
 	GetView() *tview.TreeView
 
 	// SetSelect will update treeview with the content in the commit
-	// if reference is not nil, it will be used to find changes
-	SetSelected(commit *object.Commit, reference *object.Commit)
+	// diff finds the changes, and is run by the worker
+	SetSelected(commit *object.Commit, diff treeDiffFunc)
+
+	// SetPaths sets the paths the history is limited to,
+	// which are expanded whenever a commit is shown
+	SetPaths(paths []string)
+
+	// SetTitle sets the title, which is followed by the filter if any
+	SetTitle(title string)
+
+	// SetChangedOnly sets whether only changed files and their directories are shown
+	SetChangedOnly(changedOnly bool)
+
+	// SetWhitespace sets the whitespace differences ignored, so that files
+	// only changed in them are unchanged, and the patch shown is computed again
+	SetWhitespace(mode WhitespaceMode)
+
+	// files are filtered by fuzzy matching their paths with the search,
+	// which is kept across commits
+	Searchable
 }
 
 type treeContentView struct {
 	top TopLevelView
+	worker Worker
 	view *tview.TreeView
+
+	title string
+	paths []string
+	filter string
+	changedOnly bool
+	// renameThreshold is the similarity in percent renames and copies are detected from
+	renameThreshold int
+	whitespace WhitespaceMode
+	// diffContext is the number of unchanged lines shown around changes in patches
+	diffContext int
+
+	// commit and diff are what the tree is built from,
+	// and content is what they have been computed to, or nil until then
+	commit *object.Commit
+	diff treeDiffFunc
+	content *treeContent
+	// files are the files left by the filter
+	files []*tview.TreeNode
+	// shown is the change whose patch has been selected last
+	shown *object.Change
+}
+
+// treeDiffFunc computes the tree to compare against, and the changes
+type treeDiffFunc func(ctx context.Context, tree *object.Tree) (*object.Tree, object.Changes, error)
+
+// treeContent is what a tree is built from
+type treeContent struct {
+	tree *object.Tree
+	refTree *object.Tree
+	changes object.Changes
+	renames map[*object.Change]renameInfo
 }
 
 ////////////////////////////////////////////////////////////
@@ -66,12 +118,23 @@ const (
 	NodeColorInserted = tcell.ColorGreen
 	NodeColorDeleted = tcell.ColorRed
 	NodeColorModified = tcell.ColorYellow
+	NodeColorRenamed = tcell.ColorAqua
 )
 
+// RenameSimilarityColor is the color of the similarity of a renamed or copied file
+const RenameSimilarityColor = tcell.ColorGray
+
+// HiddenCountColor is the color of the number of unchanged entries left out of a directory
+const HiddenCountColor = tcell.ColorGray
+
 type treeNodeData struct {
 	entry object.TreeEntry
 	changes object.Changes
 	state merkletrie.Action
+	// hidden is the number of unchanged entries of a directory left out
+	hidden int
+	// rename is set if the file is renamed or copied from the one of changes
+	rename *renameInfo
 }
 
 // NewTreeNodeData creates an instance of treeNodeData
@@ -89,16 +152,28 @@ func NewTreeNodeData(entry object.TreeEntry, changes object.Changes, state merkl
 ////////////////////////////////////////////////////////////
 
 // NewTreeContentView creates an instance of TreeContentView
-func NewTreeContentView(top TopLevelView, commits []*object.Commit) TreeContentView {
+// detecting renames and copies of files at least renameThreshold percent similar,
+// and ignoring the whitespace differences of mode.
+// Patches show diffContext unchanged lines around changes
+func NewTreeContentView(top TopLevelView, worker Worker, renameThreshold int, mode WhitespaceMode, diffContext int) TreeContentView {
 	treeView :=  tview.NewTreeView()
 	treeView.
 		SetBorder(true).
 		SetTitle("Current Hash Content")
 
-	return &treeContentView {
+	tv := &treeContentView {
 		top: top,
+		worker: worker,
 		view: treeView,
+		title: "Current Hash Content",
+		renameThreshold: renameThreshold,
+		whitespace: mode,
+		diffContext: diffContext,
 	}
+
+	treeView.SetSelectedFunc(tv.nodeSelected)
+
+	return tv
 }
 
 func (tv *treeContentView) GetView() *tview.TreeView {
@@ -132,7 +207,25 @@ func determineState(a, b merkletrie.Action) merkletrie.Action {
 	return state
 }
 
-func buildTree(name string, pathComponents []string, curTree *object.Tree, refTree *object.Tree, changes object.Changes, maxOpenDepth int) *tview.TreeNode {
+// pathComponent returns the component of name right under the directory of pathComponents,
+// or false if name is not under it
+func pathComponent(name string, pathComponents []string) (string, bool) {
+	tokens := strings.Split(name, "/")
+	if len(tokens) <= len(pathComponents) {
+		return "", false
+	}
+	for i, comp := range pathComponents {
+		if tokens[i] != comp {
+			return "", false
+		}
+	}
+	return tokens[len(pathComponents)], true
+}
+
+// buildTree builds the node of a directory, marking changed entries.
+// Renamed and copied files in renames are shown once, under their new path.
+// If changedOnly is true, unchanged entries are left out and counted instead
+func buildTree(name string, pathComponents []string, curTree *object.Tree, refTree *object.Tree, changes object.Changes, renames map[*object.Change]renameInfo, maxOpenDepth int, changedOnly bool) *tview.TreeNode {
 	node := tview.NewTreeNode(name)
 
 	changeByPath := make(map[string] object.Changes)
@@ -151,8 +244,26 @@ func buildTree(name string, pathComponents []string, curTree *object.Tree, refTr
 		}
 	}
 
+	// renamedFrom holds the old paths of renamed files, which are not shown
+	renamedFrom := make(map[string]bool)
+
 	for _, c := range changes {
 		action, _ := c.Action()
+		if info, ok := renames[c]; ok {
+			// either path may be out of this directory
+			if _, ok := pathComponent(c.To.Name, pathComponents); ok {
+				updateMaps(c.To.Name, c)
+			}
+			if fromComp, ok := pathComponent(c.From.Name, pathComponents); ok && !info.copied {
+				renamedFrom[c.From.Name] = true
+				// the directory of the old path is changed as well
+				if l := changeByPath[fromComp]; len(l) == 0 || l[len(l)-1] != c {
+					changeByPath[fromComp] = append(l, c)
+				}
+			}
+			continue
+		}
+
 		switch action {
 		case merkletrie.Insert:
 			updateMaps(c.To.Name, c)
@@ -193,10 +304,25 @@ func buildTree(name string, pathComponents []string, curTree *object.Tree, refTr
 
 	var subdirs []string
 	var files []string
+	hidden := 0
 	for d := range subdirsMap {
+		if changedOnly && len(changeByPath[d]) == 0 {
+			hidden++
+			continue
+		}
 		subdirs = append(subdirs, d)
 	}
 	for f := range filesMap {
+		path := strings.Join(append(pathComponents, f), "/")
+		if _, changed := changeByFullPath[path]; !changed && renamedFrom[path] {
+			// the deletion is part of the rename
+			aggState = determineState(aggState, merkletrie.Delete)
+			continue
+		}
+		if _, changed := changeByFullPath[path]; changedOnly && !changed {
+			hidden++
+			continue
+		}
 		files = append(files, f)
 	}
 	sort.Strings(subdirs)
@@ -214,7 +340,7 @@ func buildTree(name string, pathComponents []string, curTree *object.Tree, refTr
 		subChanges := changeByPath[dir]
 
 		components := append(pathComponents, dir)
-		childNode := buildTree(dir, components, child, refChild, subChanges, maxOpenDepth-1)
+		childNode := buildTree(dir, components, child, refChild, subChanges, renames, maxOpenDepth-1, changedOnly)
 		node.AddChild(childNode)
 
 		data := childNode.GetReference().(*treeNodeData)
@@ -229,7 +355,12 @@ func buildTree(name string, pathComponents []string, curTree *object.Tree, refTr
 		var state merkletrie.Action
 		if c, ok := changeByFullPath[path]; ok {
 			state, _ = c.Action()
-			if state == merkletrie.Modify && c.From.Name != c.To.Name {
+			var rename *renameInfo
+			if info, ok := renames[c]; ok {
+				rename = &info
+				state = merkletrie.Insert
+				childNode.SetColor(NodeColorRenamed)
+			} else if state == merkletrie.Modify && c.From.Name != c.To.Name {
 				if (c.From.Name == path) {
 					state = merkletrie.Delete
 					childNode.SetColor(NodeColorDeleted)
@@ -248,6 +379,8 @@ func buildTree(name string, pathComponents []string, curTree *object.Tree, refTr
 				}
 			}
 			data = NewTreeNodeData(filesMap[file], object.Changes{ c }, state)
+			data.rename = rename
+			childNode.SetText(treeNodeText(file, data))
 
 			childNode.SetSelectable(true)
 		} else {
@@ -273,15 +406,17 @@ func buildTree(name string, pathComponents []string, curTree *object.Tree, refTr
 		hash = curTree.Hash
 	}
 
-	node.SetReference(
-		NewTreeNodeData(
-			object.TreeEntry{
-				Name: strings.Join(pathComponents, "/"),
-				Mode: filemode.Dir,
-				Hash: hash,
-			},
-			changes, aggState),
-	)
+	data := NewTreeNodeData(
+		object.TreeEntry{
+			Name: strings.Join(pathComponents, "/"),
+			Mode: filemode.Dir,
+			Hash: hash,
+		},
+		changes, aggState)
+	data.hidden = hidden
+	node.SetReference(data)
+
+	node.SetText(treeNodeText(name, data))
 
 	if maxOpenDepth <= 0 && aggState == 0 {
 		node.SetExpanded(false)
@@ -290,31 +425,395 @@ func buildTree(name string, pathComponents []string, curTree *object.Tree, refTr
 	return node
 }
 
+// expandPaths expands the nodes down to each of paths under root,
+// and returns the node of the first path found
+func expandPaths(root *tview.TreeNode, paths []string) *tview.TreeNode {
+	var first *tview.TreeNode
+	for _, p := range paths {
+		node := root
+		for _, name := range strings.Split(p, "/") {
+			var found *tview.TreeNode
+			for _, child := range node.GetChildren() {
+				if treeNodeName(child) == name {
+					found = child
+					break
+				}
+			}
+			if found == nil {
+				break
+			}
+
+			node.SetExpanded(true)
+			node = found
+		}
+
+		node.SetExpanded(true)
+		if first == nil && node != root {
+			first = node
+		}
+	}
+
+	return first
+}
+
+func (tv *treeContentView) SetPaths(paths []string) {
+	tv.paths = paths
+}
+
+func (tv *treeContentView) SetTitle(title string) {
+	tv.title = title
+	tv.updateTitle()
+}
+
+func (tv *treeContentView) SetChangedOnly(changedOnly bool) {
+	if tv.changedOnly == changedOnly {
+		return
+	}
+
+	tv.changedOnly = changedOnly
+	tv.updateTitle()
+	tv.rebuild()
+}
+
+func (tv *treeContentView) SetWhitespace(mode WhitespaceMode) {
+	if tv.whitespace == mode {
+		return
+	}
+
+	tv.whitespace = mode
+	tv.updateTitle()
+	if tv.commit != nil {
+		// the changes are computed again with the files changed in whitespace left out
+		tv.showChanges(tv.commit, tv.diff)
+	}
+	if tv.shown != nil {
+		tv.showPatch(tv.shown)
+	}
+}
+
+func (tv *treeContentView) updateTitle() {
+	title := tv.title
+	if tv.changedOnly {
+		title += " (changed only)"
+	}
+	if tv.whitespace != 0 {
+		title += " (" + tv.whitespace.String() + ")"
+	}
+	if tv.filter != "" {
+		title += fmt.Sprintf(" /%s", tview.Escape(tv.filter))
+	}
+
+	tv.view.SetTitle(title)
+}
+
+func (tv *treeContentView) GetSearch() string {
+	return tv.filter
+}
+
+func (tv *treeContentView) SetSearch(query string) {
+	if tv.filter == query {
+		return
+	}
+
+	tv.filter = query
+	tv.updateTitle()
+	tv.rebuild()
+}
+
+// rebuild builds the tree again, e.g. when the filter changes
+func (tv *treeContentView) rebuild() {
+	if tv.content != nil {
+		tv.showContent(tv.content)
+	} else if tv.commit != nil {
+		// the changes are still being computed
+		tv.showChanges(tv.commit, tv.diff)
+	}
+}
+
+func (tv *treeContentView) NextMatch(forward bool) {
+	if len(tv.files) == 0 {
+		return
+	}
+
+	idx := -1
+	current := tv.view.GetCurrentNode()
+	for i, file := range tv.files {
+		if file == current {
+			idx = i
+			break
+		}
+	}
+
+	switch {
+	case idx < 0:
+		idx = 0
+	case forward:
+		idx = (idx + 1) % len(tv.files)
+	default:
+		idx = (idx - 1 + len(tv.files)) % len(tv.files)
+	}
+
+	tv.view.SetCurrentNode(tv.files[idx])
+}
+
+// treeNodeText decorates name, which may hold color tags, with what data tells about the node
+func treeNodeText(name string, data *treeNodeData) string {
+	text := name
+	if data.rename != nil {
+		verb := "renamed"
+		if data.rename.copied {
+			verb = "copied"
+		}
+		text = tview.Escape(data.changes[0].From.Name) + RenameArrow + text +
+			fmt.Sprintf(" [#%06x](%s, %d%%)[-]", RenameSimilarityColor.Hex(), verb, data.rename.similarity)
+	}
+	if data.hidden > 0 {
+		text += hiddenSuffix(data.hidden)
+	}
+	return text
+}
+
+// hiddenSuffix follows the name of a directory with hidden unchanged entries
+func hiddenSuffix(hidden int) string {
+	return fmt.Sprintf(" [#%06x](%d unchanged)[-]", HiddenCountColor.Hex(), hidden)
+}
+
 // SetSelected is called when a selection is changed
-func (tv *treeContentView) SetSelected(commit *object.Commit, reference *object.Commit) {
-	tree, _ := commit.Tree()
+func (tv *treeContentView) SetSelected(commit *object.Commit, diff treeDiffFunc) {
+	// the patch shown last is of another commit or diff
+	tv.shown = nil
+	tv.showChanges(commit, diff)
+}
+
+// referenceDiff diffs trees against the tree of reference
+func referenceDiff(reference *object.Commit) treeDiffFunc {
+	return func(ctx context.Context, tree *object.Tree) (*object.Tree, object.Changes, error) {
+		if reference == nil {
+			// every file of a commit without parents is added
+			changes, err := object.DiffTreeContext(ctx, nil, tree)
+			return nil, changes, err
+		}
+
+		refTree, err := reference.Tree()
+		if err != nil {
+			return nil, nil, err
+		}
 
-	var refTree *object.Tree
-	var changes object.Changes
-	if reference != nil {
-		refTree, _ = reference.Tree()
-		object.DiffTree(tree, refTree)
-		changes, _ = object.DiffTree(refTree, tree)
+		changes, err := object.DiffTreeContext(ctx, refTree, tree)
+		return refTree, changes, err
 	}
+}
+
+// parentDiff diffs trees against the tree of the parentIdx-th parent of commit
+func parentDiff(commit *object.Commit, parentIdx int) treeDiffFunc {
+	return func(ctx context.Context, tree *object.Tree) (*object.Tree, object.Changes, error) {
+		var reference *object.Commit
+		if parentIdx < commit.NumParents() {
+			var err error
+			if reference, err = commit.Parent(parentIdx); err != nil {
+				return nil, nil, err
+			}
+		}
 
-	root := buildTree(".", []string{}, tree, refTree, changes, MaxOpenDepth)
+		return referenceDiff(reference)(ctx, tree)
+	}
+}
 
-	tv.view.SetRoot(root).SetCurrentNode(root).
-		SetSelectedFunc(func(node *tview.TreeNode) {
-			data := node.GetReference().(*treeNodeData)
-			if len(data.changes) > 0 {
-				change := data.changes[0]
-				patch, _ := change.Patch()
-				filePatch := patch.FilePatches()
+// combinedDiff diffs trees against all parents of the merge commit,
+// only files that differ from all parents are marked as changed
+func combinedDiff(commit *object.Commit) treeDiffFunc {
+	return func(ctx context.Context, tree *object.Tree) (*object.Tree, object.Changes, error) {
+		var refTree *object.Tree
+		var changes object.Changes
+
+		// count the parents each path differs from
+		counts := make(map[string]int)
+		numParents := 0
+		err := commit.Parents().ForEach(func(parent *object.Commit) error {
+			parentTree, err := parent.Tree()
+			if err != nil {
+				return err
+			}
 
-				if len(filePatch) > 0 && !filePatch[0].IsBinary() {
-					tv.top.NotifyFileSelectionChange(filePatch[0])
-				}
+			parentChanges, err := object.DiffTreeContext(ctx, parentTree, tree)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range parentChanges {
+				counts[changePath(c)]++
+			}
+
+			// the diff against the first parent is shown for the remaining paths
+			if numParents == 0 {
+				refTree = parentTree
+				changes = parentChanges
 			}
+			numParents++
+			return nil
 		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var combined object.Changes
+		for _, c := range changes {
+			if counts[changePath(c)] == numParents {
+				combined = append(combined, c)
+			}
+		}
+
+		return refTree, combined, nil
+	}
+}
+
+// changePath returns the path of the file a change is made to
+func changePath(c *object.Change) string {
+	if c.To.Name != "" {
+		return c.To.Name
+	}
+	return c.From.Name
+}
+
+// showChanges builds the tree of the selected commit in the background.
+// diff computes the tree to compare against, and the changes
+func (tv *treeContentView) showChanges(commit *object.Commit, diff treeDiffFunc) {
+	// a patch of the previous selection is no longer needed
+	tv.worker.Cancel(JobPatch)
+
+	tv.commit = commit
+	tv.diff = diff
+	tv.content = nil
+
+	options := tv.buildOptions()
+	renameThreshold := tv.renameThreshold
+	mode := tv.whitespace
+	tv.worker.Submit(JobTree, func(ctx context.Context) func() {
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil
+		}
+
+		refTree, changes, err := diff(ctx, tree)
+		if err != nil {
+			return nil
+		}
+
+		changes, err = filterWhitespaceChanges(ctx, changes, mode)
+		if err != nil {
+			return nil
+		}
+
+		changes, renames, err := detectRenames(ctx, changes, renameThreshold)
+		if err != nil {
+			return nil
+		}
+
+		content := &treeContent{tree: tree, refTree: refTree, changes: changes, renames: renames}
+		root, current, files := buildContentTree(content, options)
+
+		return func() {
+			tv.content = content
+			tv.setRoot(root, current, files)
+		}
+	})
+}
+
+// showContent rebuilds the tree shown in the background, e.g. when the filter changes
+func (tv *treeContentView) showContent(content *treeContent) {
+	options := tv.buildOptions()
+	tv.worker.Submit(JobTree, func(ctx context.Context) func() {
+		root, current, files := buildContentTree(content, options)
+
+		return func() {
+			tv.setRoot(root, current, files)
+		}
+	})
+}
+
+func (tv *treeContentView) setRoot(root, current *tview.TreeNode, files []*tview.TreeNode) {
+	tv.files = files
+	tv.view.SetRoot(root).SetCurrentNode(current)
+}
+
+// treeBuildOptions are the settings of the view a tree is built with
+type treeBuildOptions struct {
+	paths []string
+	filter string
+	changedOnly bool
+}
+
+func (tv *treeContentView) buildOptions() treeBuildOptions {
+	return treeBuildOptions{
+		paths: tv.paths,
+		filter: tv.filter,
+		changedOnly: tv.changedOnly,
+	}
+}
+
+// buildContentTree builds the tree of content with paths expanded,
+// and the files not matching the filter removed.
+// It returns the root, the node to be selected and the files left by the filter
+func buildContentTree(content *treeContent, options treeBuildOptions) (*tview.TreeNode, *tview.TreeNode, []*tview.TreeNode) {
+	root := buildTree(".", []string{}, content.tree, content.refTree, content.changes, content.renames, MaxOpenDepth, options.changedOnly)
+
+	var files []*tview.TreeNode
+	if options.filter != "" {
+		files = filterTree(root, options.filter)
+	}
+
+	current := expandPaths(root, options.paths)
+	if len(files) > 0 {
+		current = files[0]
+	}
+	if current == nil {
+		current = root
+	}
+
+	return root, current, files
+}
+
+// nodeSelected computes the patch of the selected file in the background
+func (tv *treeContentView) nodeSelected(node *tview.TreeNode) {
+	data := node.GetReference().(*treeNodeData)
+	if len(data.changes) == 0 {
+		return
+	}
+
+	tv.showPatch(data.changes[0])
+}
+
+// showPatch computes the patch of change in the background
+func (tv *treeContentView) showPatch(change *object.Change) {
+	tv.shown = change
+	mode := tv.whitespace
+	diffContext := tv.diffContext
+	tv.worker.Submit(JobPatch, func(ctx context.Context) func() {
+		patch, err := change.PatchContext(ctx)
+		if err != nil {
+			return nil
+		}
+
+		filePatch := patch.FilePatches()
+		if len(filePatch) == 0 {
+			return nil
+		}
+
+		if filePatch[0].IsBinary() {
+			binary, err := newBinaryDiff(ctx, change)
+			if err != nil {
+				return nil
+			}
+
+			return func() {
+				tv.top.NotifyBinaryFileSelectionChange(binary)
+			}
+		}
+
+		// lines are highlighted and folded here, not to block the UI on large files
+		text := newTextDiff(newWhitespaceFilePatch(filePatch[0], mode), diffContext)
+		return func() {
+			tv.top.NotifyFileSelectionChange(text)
+		}
+	})
 }
@@ -0,0 +1,64 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+)
+
+func TestBuildTreeRootCommit(t *testing.T) {
+	tr := newTestRepository(t)
+	commit := tr.commit(map[string]string{"a/b/x.txt": "x\n", "y.txt": "y\n"})
+
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refTree, changes, err := referenceDiff(nil)(context.Background(), tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, changedOnly := range []bool{false, true} {
+		root := buildTree(".", []string{}, tree, refTree, changes, nil, MaxOpenDepth, changedOnly)
+		nodes := treeNodes(root, make(map[string]*tview.TreeNode))
+
+		for _, name := range []string{"a", "a/b", "a/b/x.txt", "y.txt"} {
+			node, ok := nodes[name]
+			if !ok {
+				t.Errorf("changedOnly=%v: no node for %s", changedOnly, name)
+				continue
+			}
+			if state := node.GetReference().(*treeNodeData).state; state != merkletrie.Insert {
+				t.Errorf("changedOnly=%v: %s is %v, want inserted", changedOnly, name, state)
+			}
+		}
+	}
+}
@@ -25,8 +25,9 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"os"
 
 	log "github.com/sirupsen/logrus"
 
@@ -34,8 +35,8 @@ import (
 	"github.com/rivo/tview"
 
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
-	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
 )
 
 ////////////////////////////////////////////////////////////
@@ -52,31 +53,126 @@ var TableFormatting struct {
 // types
 ////////////////////////////////////////////////////////////
 
+// Options customizes how the ui loads and shows the repository
+type Options struct {
+	// PageSize is the number of commits to load at a time
+	PageSize int
+
+	// Rev is the revision or the range of revisions(A..B) to show the history of.
+	// HEAD is used if empty
+	Rev string
+
+	// Paths limits the history to commits changing any of them
+	Paths []string
+
+	// Filter limits the history by author and dates
+	Filter LogFilter
+
+	// Columns are the columns of the commit list.
+	// DefaultCommitColumns are used if empty
+	Columns []CommitColumn
+
+	// RelativeDate shows commit dates relative to now, e.g. 3 days ago
+	RelativeDate bool
+
+	// DiffContext is the number of unchanged lines shown around changes in diffs.
+	// The other ones are folded
+	DiffContext int
+
+	// RenameThreshold is the similarity in percent from which
+	// files are detected as renamed or copied. 0 disables the detection
+	RenameThreshold int
+
+	// Whitespace is the whitespace differences ignored by diffs
+	Whitespace WhitespaceMode
+
+	// BookmarksPath is the file bookmarks are stored in
+	BookmarksPath string
+
+	// RepoKey identifies the repository in the bookmarks file.
+	// Bookmarks are not saved if empty
+	RepoKey string
+}
+
 // an implementation of TopLevelView
 type topLevelView struct {
 	app *tview.Application
 	repo *git.Repository
 	commits []*object.Commit
+	rev string
+	paths []string
+	pageSize int
+	loader CommitLoader
+	loadingCommits bool
+	// moreCommits is whether the loader has more commits, as of its last page
+	moreCommits bool
+	// pickaxe limits the history to commits changing what it looks for,
+	// and filter to the ones matching it. searchStopped is set once
+	// looking for more commits passing them has been stopped
+	pickaxe *pickaxe
+	searchStopped bool
+	filter LogFilter
+	// pendingCommit is selected once it is loaded
+	pendingCommit *object.Commit
+	worker Worker
 
 	diffMode DiffMode
+	// parentIdx is the parent DiffModeSingle compares merge commits against
+	parentIdx int
+	showMerges bool
+	changedOnly bool
+	splitDiff bool
+	whitespace WhitespaceMode
+	relativeDate bool
 	head *object.Commit
 	curSelection *object.Commit
 
+	// marks are set with m<letter> and jumped to with '<letter>,
+	// and pendingKey is the m or ' waiting for its letter
+	marks map[rune]*object.Commit
+	pendingKey rune
+	bookmarks BookmarkStore
+
 	listView CommitListView
 	detailView CommitDetailView
 	treeView TreeContentView
 	diffView DiffView
+	refPicker RefPickerView
+	refListView RefListView
+	pathPrompt PathPromptView
+	searchPrompt SearchPromptView
+	pickaxePrompt PickaxePromptView
+	filterBar FilterBarView
+	grepView GrepView
+	gotoPrompt GotoPromptView
+	bookmarkPrompt BookmarkPromptView
+	bookmarkList BookmarkListView
+
+	pages *tview.Pages
+	popup tview.Primitive
+	popupFocus tview.Primitive
 
 	curFocusView interface{}
 }
 
+// RefListWidth is the width of the refs panel
+const RefListWidth = 30
+
+// popupPage is the name of the page popups are shown in
+const popupPage = "popup"
+
 // NewTopLevelView creates an instance of TopLevelView
-func NewTopLevelView(app *tview.Application, repo *git.Repository, commits []*object.Commit) TopLevelView {
+func NewTopLevelView(app *tview.Application, repo *git.Repository, worker Worker, bookmarks BookmarkStore, opts Options) TopLevelView {
 	topView := topLevelView{
 		app: app,
 		repo: repo,
-		commits: commits,
-		head: commits[0],
+		pageSize: opts.PageSize,
+		filter: opts.Filter,
+		relativeDate: opts.RelativeDate,
+		whitespace: opts.Whitespace,
+		marks: make(map[rune]*object.Commit),
+		bookmarks: bookmarks,
+		worker: worker,
 	}
 
 	return &topView
@@ -88,24 +184,421 @@ func (tv *topLevelView) NotifyCommitSelectionChange(commit *object.Commit) {
 	}
 
 	tv.curSelection = commit
+	tv.parentIdx = 0
 
-	tv.detailView.SetSelected(commit)
 	tv.updateTreeView()
 }
 
-func (tv *topLevelView) NotifyFileSelectionChange(patch diff.FilePatch) {
-	tv.diffView.SetFilePatch(patch)
+func (tv *topLevelView) NotifyFileSelectionChange(text *textDiff) {
+	tv.diffView.SetText(text)
+}
+
+func (tv *topLevelView) NotifyBinaryFileSelectionChange(binary *binaryDiff) {
+	tv.diffView.SetBinary(binary)
+}
+
+func (tv *topLevelView) LoadMoreCommits() {
+	if tv.loadingCommits || tv.searchStopped || !tv.moreCommits {
+		return
+	}
+
+	tv.loadingCommits = true
+	loader := tv.loader
+	tv.worker.Submit(JobCommits, func(ctx context.Context) func() {
+		commits, err := loader.LoadMore(ctx)
+		if ctx.Err() != nil {
+			err = nil
+		}
+		hasMore := loader.HasMore()
+		scanned := loader.Scanned()
+
+		return func() {
+			tv.loadingCommits = false
+			tv.moreCommits = hasMore
+			if err != nil {
+				// the loader is closed, so the history ends here
+				tv.listView.SetStatus(fmt.Sprintf("(failed: %v)", err))
+			}
+			if tv.filtering() && tv.pendingCommit == nil {
+				tv.listView.SetProgress(fmt.Sprintf("searching… %d commits scanned (Esc to stop)", scanned))
+			}
+			tv.commits = append(tv.commits, commits...)
+			tv.listView.AppendCommits(commits, hasMore)
+			tv.selectPendingCommit()
+		}
+	})
+}
+
+func (tv *topLevelView) SelectCommit(commit *object.Commit) {
+	// merges have to be listed to be selected
+	if commit.NumParents() > 1 && !tv.showMerges {
+		tv.showMerges = true
+		tv.listView.SetShowMerges(true)
+	}
+
+	tv.pendingCommit = commit
+	tv.listView.SetProgress(fmt.Sprintf("looking for %s…", shortHash(commit)))
+	tv.selectPendingCommit()
+}
+
+// selectPendingCommit selects the commit requested by SelectCommit once it is loaded,
+// loading more commits until then
+func (tv *topLevelView) selectPendingCommit() {
+	commit := tv.pendingCommit
+	if commit == nil {
+		return
+	}
+
+	if tv.listView.SelectCommit(commit) {
+		tv.pendingCommit = nil
+		tv.listView.SetProgress("")
+		tv.focusListView()
+		return
+	}
+
+	if tv.searchStopped {
+		// looking for the commit stopped along with the search
+		tv.pendingCommit = nil
+		tv.listView.SetProgress("")
+		return
+	}
+
+	if !tv.moreCommits {
+		// the commit is not part of the history, so show its own
+		rev := tv.rev
+		if rev == "" {
+			rev = string(plumbing.HEAD)
+		}
+		tv.pendingCommit = nil
+		tv.openLog(commit.Hash.String(), tv.paths,
+			fmt.Sprintf("(not in %s)", shortRevision(rev)))
+		return
+	}
+
+	tv.LoadMoreCommits()
+}
+
+func (tv *topLevelView) NotifyRevisionChange(rev string) {
+	tv.openLog(rev, tv.paths, "")
+}
+
+func (tv *topLevelView) NotifyPathsChange(paths []string) {
+	tv.openLog(tv.rev, cleanPaths(paths), "")
+}
+
+func (tv *topLevelView) NotifyPickaxeChange(p *pickaxe) {
+	tv.pickaxe = p
+	tv.openLog(tv.rev, tv.paths, "")
+}
+
+func (tv *topLevelView) NotifyFilterChange(filter LogFilter) {
+	tv.filter = filter
+	tv.openLog(tv.rev, tv.paths, "")
+}
+
+func (tv *topLevelView) NotifyBookmarksChange() {
+	tv.updateMarks()
+}
+
+// LastJumpMark is the mark set to the commit selected before jumping to a mark
+const LastJumpMark = '\''
+
+// setMark marks the selected commit with the letter
+func (tv *topLevelView) setMark(letter rune) {
+	if tv.curSelection == nil || !isMarkLetter(letter) {
+		return
+	}
+
+	tv.marks[letter] = tv.curSelection
+	tv.updateMarks()
+}
+
+// jumpToMark selects the commit marked with the letter,
+// remembering the selected one to jump back with ''
+func (tv *topLevelView) jumpToMark(letter rune) {
+	commit, ok := tv.marks[letter]
+	if !ok {
+		return
+	}
+
+	if tv.curSelection != nil {
+		tv.marks[LastJumpMark] = tv.curSelection
+	}
+	tv.SelectCommit(commit)
+}
+
+// isMarkLetter returns true if the letter can name a mark
+func isMarkLetter(letter rune) bool {
+	return (letter >= 'a' && letter <= 'z') || (letter >= 'A' && letter <= 'Z')
+}
+
+// updateMarks tags the commits having marks or bookmarks in the commit list
+func (tv *topLevelView) updateMarks() {
+	marks := make(map[plumbing.Hash][]string)
+	for letter := 'A'; letter <= 'z'; letter++ {
+		if commit, ok := tv.marks[letter]; ok && isMarkLetter(letter) {
+			marks[commit.Hash] = append(marks[commit.Hash], "'" + string(letter))
+		}
+	}
+	for _, b := range tv.bookmarks.List() {
+		marks[b.Hash] = append(marks[b.Hash], "★" + b.Name)
+	}
+
+	tv.listView.SetMarks(marks)
+}
+
+// filtering returns true if the history is limited by the filter, the pickaxe or paths
+func (tv *topLevelView) filtering() bool {
+	return !tv.filter.IsEmpty() || tv.pickaxe != nil || len(tv.paths) > 0
+}
+
+// stopSearch stops searching the history for commits passing the filters,
+// keeping the commits found so far
+func (tv *topLevelView) stopSearch() {
+	if !tv.filtering() || tv.searchStopped || !tv.moreCommits {
+		return
+	}
+
+	tv.worker.Cancel(JobCommits)
+	tv.loadingCommits = false
+	tv.searchStopped = true
+
+	tv.listView.AppendCommits(nil, false)
+	tv.listView.SetStatus("(stopped)")
+
+	// the page being searched keeps what it has found once it stops,
+	// which may be the commit looked for
+	loader := tv.loader
+	tv.worker.Submit(JobCommits, func(ctx context.Context) func() {
+		commits := loader.Flush()
+
+		return func() {
+			tv.commits = append(tv.commits, commits...)
+			tv.listView.AppendCommits(commits, false)
+			tv.selectPendingCommit()
+		}
+	})
+}
+
+// filterDescriptions describes the filters limiting the history
+func (tv *topLevelView) filterDescriptions() []string {
+	filters := tv.filter.Descriptions()
+	if tv.pickaxe != nil {
+		filters = append(filters, tv.pickaxe.String())
+	}
+	return filters
+}
+
+// commitFilter returns a filter accepting commits passing the log filter,
+// changing the paths and matching the pickaxe, or nil to accept all commits
+func (tv *topLevelView) commitFilter() CommitFilter {
+	filter := tv.filter
+	p := tv.pickaxe
+	paths := tv.paths
+	if !tv.filtering() {
+		return nil
+	}
+
+	var changesPaths CommitFilter
+	if len(paths) > 0 {
+		changesPaths = pathFilter(paths)
+	}
+
+	// the history is older than Since once SinceSlop commits in a row are.
+	// A commit checked again after a cancellation is counted once
+	older := 0
+	var lastOlder plumbing.Hash
+
+	// cheaper checks come first
+	return func(ctx context.Context, commit *object.Commit) (bool, error) {
+		if filter.older(commit) {
+			if commit.Hash != lastOlder {
+				lastOlder = commit.Hash
+				older++
+			}
+			if older >= SinceSlop {
+				return false, io.EOF
+			}
+			return false, nil
+		}
+		older = 0
+		lastOlder = plumbing.ZeroHash
+
+		if !filter.matches(commit) {
+			return false, nil
+		}
+		if changesPaths != nil {
+			if changed, err := changesPaths(ctx, commit); err != nil || !changed {
+				return false, err
+			}
+		}
+		if p == nil {
+			return true, nil
+		}
+
+		return p.matches(ctx, commit, paths)
+	}
+}
+
+func (tv *topLevelView) ClosePopup() {
+	if tv.popup == nil {
+		return
+	}
+
+	tv.pages.RemovePage(popupPage)
+	tv.app.SetFocus(tv.popupFocus)
+	tv.popup = nil
+	tv.popupFocus = nil
+}
+
+func (tv *topLevelView) SetFocus(p tview.Primitive) {
+	tv.app.SetFocus(p)
+}
+
+// showPopup shows p in the middle of the screen, and focuses it.
+// A width or a height of 0 fills the screen but PopupMargin
+func (tv *topLevelView) showPopup(p tview.Primitive, width, height int) {
+	tv.ClosePopup()
+
+	column := addCentered(tview.NewFlex().SetDirection(tview.FlexRow), p, height)
+	centered := addCentered(tview.NewFlex(), column, width)
+
+	tv.popup = p
+	tv.popupFocus = tv.app.GetFocus()
+	tv.pages.AddPage(popupPage, centered, true, true)
+	tv.app.SetFocus(p)
+}
+
+// PopupMargin is the space around popups filling the screen
+const PopupMargin = 2
+
+// addCentered adds p in the middle of flex with the size,
+// or filling flex but PopupMargin if size is 0
+func addCentered(flex *tview.Flex, p tview.Primitive, size int) *tview.Flex {
+	if size <= 0 {
+		return flex.
+			AddItem(nil, PopupMargin, 0, false).
+			AddItem(p, 0, 1, true).
+			AddItem(nil, PopupMargin, 0, false)
+	}
+
+	return flex.
+		AddItem(nil, 0, 1, false).
+		AddItem(p, size, 1, true).
+		AddItem(nil, 0, 1, false)
+}
+
+// openLog shows the history of rev limited to paths in the background,
+// and focuses the commit list. status, if not empty, is shown in its title
+func (tv *topLevelView) openLog(rev string, paths []string, status string) {
+	repo := tv.repo
+	tv.worker.Submit(JobLog, func(ctx context.Context) func() {
+		head, iter, err := openLog(ctx, repo, rev)
+		if err != nil {
+			log.Printf("Failed to open the history of %s: %v\n", rev, err)
+			// the history shown so far stays, noting why it has not changed
+			return func() {
+				tv.listView.SetStatus(fmt.Sprintf("(failed to open %s: %v)", shortRevision(rev), err))
+			}
+		}
+
+		return func() {
+			tv.setLog(rev, paths, head, iter)
+			if status != "" {
+				tv.listView.SetStatus(status)
+			}
+			tv.focusListView()
+		}
+	})
+}
+
+func (tv *topLevelView) focusListView() {
+	tv.app.SetFocus(tv.listView.GetView())
+	tv.curFocusView = tv.listView
+}
+
+// setLog replaces the history shown in the commit list
+func (tv *topLevelView) setLog(rev string, paths []string, head *object.Commit, iter object.CommitIter) {
+	// a page of the previous history may still be loading,
+	// so its iterator is closed once the page has stopped
+	tv.worker.Cancel(JobCommits)
+	tv.loadingCommits = false
+	if prev := tv.loader; prev != nil {
+		tv.worker.Run(func(ctx context.Context) func() {
+			prev.Close()
+			return nil
+		})
+	}
+
+	tv.rev = rev
+	tv.paths = paths
+	tv.head = head
+	tv.commits = nil
+	tv.searchStopped = false
+	tv.pendingCommit = nil
+	tv.loader = NewFilteredCommitLoader(iter, tv.pageSize, tv.commitFilter())
+	tv.moreCommits = true
+
+	tv.listView.Reset(rev, paths)
+	tv.listView.SetFilters(tv.filterDescriptions(), tv.filter.dropsCommits() || tv.pickaxe != nil)
+	tv.treeView.SetPaths(paths)
+	tv.LoadMoreCommits()
+}
+
+// loadDecorations lists the refs shown next to commits in the commit list
+func (tv *topLevelView) loadDecorations() {
+	tv.worker.Submit(JobDecorations, func(ctx context.Context) func() {
+		decorations, err := refDecorations(tv.repo)
+		if err != nil {
+			log.Printf("Failed to list refs: %v\n", err)
+		}
+
+		return func() {
+			tv.listView.SetDecorations(decorations)
+		}
+	})
 }
 
 // afterViewInit is called after all children views are created
-func (tv *topLevelView) afterViewInit(lv CommitListView, dv CommitDetailView, tcv TreeContentView, dfv DiffView) {
+func (tv *topLevelView) afterViewInit(lv CommitListView, dv CommitDetailView, tcv TreeContentView, dfv DiffView, rpv RefPickerView, rlv RefListView, ppv PathPromptView, spv SearchPromptView, pkv PickaxePromptView, fbv FilterBarView, gv GrepView, gpv GotoPromptView, bpv BookmarkPromptView, blv BookmarkListView, pages *tview.Pages) {
 	tv.listView = lv
 	tv.detailView = dv
 	tv.treeView = tcv
 	tv.diffView = dfv
+	tv.refPicker = rpv
+	tv.refListView = rlv
+	tv.pathPrompt = ppv
+	tv.filterBar = fbv
+	tv.grepView = gv
+	tv.gotoPrompt = gpv
+	tv.bookmarkPrompt = bpv
+	tv.bookmarkList = blv
+	tv.pickaxePrompt = pkv
+	tv.searchPrompt = spv
+	tv.pages = pages
 
 	tv.curFocusView = lv
 	tv.app.SetInputCapture(func (event *tcell.EventKey) *tcell.EventKey {
+		if tv.popup != nil {
+			// the popup handles all keys
+			return event
+		}
+
+		if key := tv.pendingKey; key != 0 {
+			// the letter following m or ' names the mark
+			tv.pendingKey = 0
+			if event.Key() != tcell.KeyRune {
+				return nil
+			}
+			if key == 'm' {
+				tv.setMark(event.Rune())
+			} else {
+				tv.jumpToMark(event.Rune())
+			}
+			tv.app.Draw()
+			return nil
+		}
+
 		switch event.Key() {
 		case tcell.KeyRune:
 			switch event.Rune() {
@@ -113,6 +606,119 @@ func (tv *topLevelView) afterViewInit(lv CommitListView, dv CommitDetailView, tc
 				tv.switchMode()
 				tv.app.Draw()
 				return nil
+			case 'M':
+				tv.showMerges = !tv.showMerges
+				tv.listView.SetShowMerges(tv.showMerges)
+				tv.app.Draw()
+				return nil
+			case 'c':
+				tv.changedOnly = !tv.changedOnly
+				tv.treeView.SetChangedOnly(tv.changedOnly)
+				tv.app.Draw()
+				return nil
+			case 'v':
+				tv.splitDiff = !tv.splitDiff
+				tv.diffView.SetSplit(tv.splitDiff)
+				tv.app.Draw()
+				return nil
+			case 'w':
+				tv.toggleWhitespace(IgnoreAllSpace)
+				tv.app.Draw()
+				return nil
+			case 'W':
+				tv.toggleWhitespace(IgnoreSpaceChange)
+				tv.app.Draw()
+				return nil
+			case 'e':
+				tv.toggleWhitespace(IgnoreBlankLines)
+				tv.app.Draw()
+				return nil
+			case 'p':
+				tv.switchParent()
+				tv.app.Draw()
+				return nil
+			case 'd':
+				tv.relativeDate = !tv.relativeDate
+				tv.listView.SetRelativeDate(tv.relativeDate)
+				tv.app.Draw()
+				return nil
+			case 'o':
+				tv.listView.SortByNextColumn()
+				tv.app.Draw()
+				return nil
+			case 'O':
+				tv.listView.ReverseSort()
+				tv.app.Draw()
+				return nil
+			case 'S', 'R':
+				// R searches a regular expression like git log -G,
+				// whose letter is left for tview to move to the end
+				var query string
+				if tv.pickaxe != nil {
+					query = tv.pickaxe.query
+				}
+				tv.pickaxePrompt.Open(query, event.Rune() == 'R')
+				tv.showPopup(tv.pickaxePrompt.GetView(), 60, 3)
+				tv.app.Draw()
+				return nil
+			case ':':
+				tv.gotoPrompt.Open()
+				tv.showPopup(tv.gotoPrompt.GetView(), 60, 3)
+				tv.app.Draw()
+				return nil
+			case 'f':
+				if tv.curSelection != nil {
+					tv.showPopup(tv.grepView.GetView(), 0, 0)
+					tv.grepView.Open(tv.curSelection)
+					tv.app.Draw()
+				}
+				return nil
+			case 'F':
+				tv.filterBar.SetFilter(tv.filter)
+				tv.showPopup(tv.filterBar.GetView(), 120, 3)
+				tv.app.Draw()
+				return nil
+			case 'L':
+				tv.pathPrompt.SetPaths(tv.paths)
+				tv.showPopup(tv.pathPrompt.GetView(), 60, 3)
+				tv.app.Draw()
+				return nil
+			case '/':
+				target := tv.searchTarget()
+				tv.searchPrompt.Open(target, target.GetSearch())
+				tv.showPopup(tv.searchPrompt.GetView(), 60, 3)
+				tv.app.Draw()
+				return nil
+			case 'n', 'N':
+				tv.searchTarget().NextMatch(event.Rune() == 'n')
+				tv.app.Draw()
+				return nil
+			case 'm', '\'':
+				tv.pendingKey = event.Rune()
+				return nil
+			case 'B':
+				if tv.curSelection != nil {
+					tv.bookmarkPrompt.Open(tv.curSelection)
+					tv.showPopup(tv.bookmarkPrompt.GetView(), 60, 3)
+					tv.app.Draw()
+				}
+				return nil
+			case 'b':
+				tv.bookmarkList.Reload()
+				tv.showPopup(tv.bookmarkList.GetView(), 80, 20)
+				tv.app.Draw()
+				return nil
+			case 'r':
+				tv.refPicker.Reload()
+				tv.showPopup(tv.refPicker.GetView(), 60, 20)
+				tv.app.Draw()
+				return nil
+			}
+		case tcell.KeyEscape:
+			if tv.filtering() && !tv.searchStopped && tv.moreCommits {
+				tv.stopSearch()
+				tv.app.Draw()
+				return nil
 			}
 		case tcell.KeyTab:
 			tv.moveFocus(true)
@@ -126,36 +732,93 @@ func (tv *topLevelView) afterViewInit(lv CommitListView, dv CommitDetailView, tc
 
 		return event
 	})
+}
 
-	tv.NotifyCommitSelectionChange(tv.head)
+// searchTarget returns the view searched with the search prompt,
+// which is the focused view if it can be searched, or the commit list
+func (tv *topLevelView) searchTarget() Searchable {
+	if target, ok := tv.curFocusView.(Searchable); ok {
+		return target
+	}
+	return tv.listView
 }
 
 func (tv *topLevelView) updateTreeView() {
 	commit := tv.curSelection
-	// compute diff
-	var reference *object.Commit
-	if tv.diffMode == DiffModeSingle {
-		reference, _ = commit.Parent(0)
-	} else {
-		reference = tv.head
+	if commit == nil {
+		// no commit has been listed yet
+		return
+	}
+	title := "Current Hash Content"
+
+	// parents are loaded by the worker
+	var diff treeDiffFunc
+	switch {
+	case tv.diffMode == DiffModeAcc:
+		diff = referenceDiff(tv.head)
+
+		rev := tv.rev
+		if rev == "" {
+			rev = string(plumbing.HEAD)
+		}
+		title += fmt.Sprintf(" (vs %s)", tview.Escape(shortRevision(rev)))
+	case tv.diffMode == DiffModeCombined && commit.NumParents() > 1:
+		diff = combinedDiff(commit)
+		title += " (combined)"
+	default:
+		diff = parentDiff(commit, tv.parentIdx)
+		if commit.NumParents() > 1 {
+			title += fmt.Sprintf(" (vs parent %d/%d)", tv.parentIdx+1, commit.NumParents())
+		} else if tv.diffMode == DiffModeCombined {
+			// only merges have a combined diff
+			title += " (not a merge, combined does not apply)"
+		}
 	}
 
-	tv.treeView.SetSelected(commit, reference)
+	tv.detailView.SetSelected(commit, diff)
+	tv.treeView.SetSelected(commit, diff)
+	tv.treeView.SetTitle(title)
 }
 
 // switchMode switches diff mode
 func (tv *topLevelView) switchMode() {
-	if tv.diffMode == DiffModeSingle {
+	switch tv.diffMode {
+	case DiffModeSingle:
 		tv.diffMode = DiffModeAcc
-	} else {
+	case DiffModeAcc:
+		tv.diffMode = DiffModeCombined
+	default:
 		tv.diffMode = DiffModeSingle
 	}
 	tv.updateTreeView()
 }
 
+// switchParent makes DiffModeSingle compare a merge commit against its next parent
+func (tv *topLevelView) switchParent() {
+	if tv.curSelection == nil {
+		return
+	}
+
+	numParents := tv.curSelection.NumParents()
+	if numParents < 2 {
+		return
+	}
+
+	tv.parentIdx = (tv.parentIdx + 1) % numParents
+	tv.diffMode = DiffModeSingle
+	tv.updateTreeView()
+}
+
+// toggleWhitespace toggles ignoring the whitespace differences of mode in diffs
+func (tv *topLevelView) toggleWhitespace(mode WhitespaceMode) {
+	tv.whitespace ^= mode
+	tv.treeView.SetWhitespace(tv.whitespace)
+}
+
 // moveFocus moves focus to the next view
 func (tv *topLevelView) moveFocus(forward bool) {
 	views := []interface{} {
+		tv.refListView,
 		tv.listView,
 		tv.treeView,
 		tv.diffView,
@@ -163,6 +826,7 @@ func (tv *topLevelView) moveFocus(forward bool) {
 	}
 
 	primitives := []tview.Primitive {
+		tv.refListView.GetView(),
 		tv.listView.GetView(),
 		tv.treeView.GetView(),
 		tv.diffView.GetView(),
@@ -189,53 +853,68 @@ func (tv *topLevelView) moveFocus(forward bool) {
 // internal functions
 ////////////////////////////////////////////////////////////
 
-func makeViewRoot(app *tview.Application, repo *git.Repository) {
-	log.Print("Loading commit logs")
-	var commits []*object.Commit
-	commitIter, err := repo.Log(&git.LogOptions{
-		Order: git.LogOrderCommitterTime,
-	})
-
+func makeViewRoot(app *tview.Application, repo *git.Repository, opts Options) {
+	// commits are loaded in the background once the application starts
+	paths := cleanPaths(opts.Paths)
+	head, commitIter, err := openLog(context.Background(), repo, opts.Rev)
 	if err != nil {
-		log.Printf("Failed to get log: %v\n", err)
-		os.Exit(1)
+		log.Fatalf("Failed to get log: %v\n", err)
 	}
 
-	for idx := 1; idx < 100; idx++ {
-		commit, err := commitIter.Next()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			panic(err)
-		}
+	worker := NewWorker(app)
 
-		commits = append(commits, commit)
+	bookmarksPath := opts.BookmarksPath
+	if opts.RepoKey == "" {
+		bookmarksPath = ""
 	}
-
-	if len(commits) == 0 {
-		log.Fatal("No commits found")
+	bookmarks, bookmarksErr := NewBookmarkStore(bookmarksPath, opts.RepoKey)
+	if bookmarksErr != nil {
+		log.Printf("Failed to load bookmarks: %v\n", bookmarksErr)
 	}
 
 	log.Print("Creating views")
-	topView := NewTopLevelView(app, repo, commits)
+	topView := NewTopLevelView(app, repo, worker, bookmarks, opts)
 
-	cv := NewCommitListView(topView, commits)
-	dv := NewCommitDetailView(topView)
-	tv := NewTreeContentView(topView, commits)
+	const HasMore = true
+	cv := NewCommitListView(topView, nil, HasMore, opts.Columns)
+	cv.SetRelativeDate(opts.RelativeDate)
+	dv := NewCommitDetailView(topView, worker)
+	tv := NewTreeContentView(topView, worker, opts.RenameThreshold, opts.Whitespace, opts.DiffContext)
 	dfv := NewDiffView(topView)
-
-	topView.(*topLevelView).afterViewInit(cv, dv, tv, dfv)
+	rpv := NewRefPickerView(topView, worker, repo)
+	rlv := NewRefListView(topView, worker, repo)
+	ppv := NewPathPromptView(topView)
+	pkv := NewPickaxePromptView(topView)
+	fbv := NewFilterBarView(topView)
+	gv := NewGrepView(topView, worker)
+	gpv := NewGotoPromptView(topView, worker, repo)
+	spv := NewSearchPromptView(topView)
+	bpv := NewBookmarkPromptView(topView, bookmarks)
+	blv := NewBookmarkListView(topView, worker, repo, bookmarks)
 
 	// layout views
 	topPanel := tview.NewFlex().
+		AddItem(rlv.GetView(), RefListWidth, 0, false).
 		AddItem(cv.GetView(), 0, 1, true).
 		AddItem(tv.GetView(), 0, 1, false)
 
-	root := tview.NewFlex().
+	mainPanel := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(topPanel, 0, 1, true).
 		AddItem(dfv.GetView(), 0, 1, false)
 
+	root := tview.NewPages().
+		AddPage("main", mainPanel, true, true)
+
+	topView.(*topLevelView).afterViewInit(cv, dv, tv, dfv, rpv, rlv, ppv, spv, pkv, fbv, gv, gpv, bpv, blv, root)
+	topView.(*topLevelView).setLog(opts.Rev, paths, head, commitIter)
+	topView.(*topLevelView).updateMarks()
+	if bookmarksErr != nil {
+		cv.SetStatus(fmt.Sprintf("(failed to load bookmarks: %v)", bookmarksErr))
+	}
+	rlv.Reload()
+	topView.(*topLevelView).loadDecorations()
+
 	const FullScreen = true
 	app.SetRoot(root, FullScreen)
 }
@@ -258,11 +937,11 @@ func initFormatting() {
 ////////////////////////////////////////////////////////////
 
 // Run initializes the views, and start the event handler loop
-func Run(repo *git.Repository) error {
+func Run(repo *git.Repository, opts Options) error {
 	initFormatting()
 
 	app := tview.NewApplication()
-	makeViewRoot(app, repo)
+	makeViewRoot(app, repo, opts)
 
 	log.Printf("Starting application")
 	if err := app.Run(); err != nil {
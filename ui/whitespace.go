@@ -0,0 +1,291 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	gitdiff "gopkg.in/src-d/go-git.v4/utils/diff"
+)
+
+// WhitespaceMode specifies the whitespace differences ignored by diffs
+type WhitespaceMode int
+
+const (
+	// IgnoreAllSpace ignores whitespace when comparing lines, as git diff -w
+	IgnoreAllSpace WhitespaceMode = 1 << iota
+	// IgnoreSpaceChange ignores changes in the amount of whitespace, as git diff -b
+	IgnoreSpaceChange
+	// IgnoreBlankLines ignores changes whose lines are all blank, as git diff --ignore-blank-lines
+	IgnoreBlankLines
+)
+
+// String returns the git diff options of the mode
+func (m WhitespaceMode) String() string {
+	var options []string
+	if m&IgnoreAllSpace != 0 {
+		options = append(options, "-w")
+	}
+	if m&IgnoreSpaceChange != 0 {
+		options = append(options, "-b")
+	}
+	if m&IgnoreBlankLines != 0 {
+		options = append(options, "--ignore-blank-lines")
+	}
+	return strings.Join(options, " ")
+}
+
+// normalizeLine returns what line is compared as
+func (m WhitespaceMode) normalizeLine(line string) string {
+	switch {
+	case m&IgnoreAllSpace != 0:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, line)
+	case m&IgnoreSpaceChange != 0:
+		// runs of whitespace are a single space, and trailing ones are dropped
+		var b strings.Builder
+		space := false
+		for _, r := range strings.TrimRightFunc(line, unicode.IsSpace) {
+			if unicode.IsSpace(r) {
+				if !space {
+					b.WriteRune(' ')
+				}
+				space = true
+				continue
+			}
+			space = false
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+
+	return line
+}
+
+// sameContent returns true if from and to only differ in whitespace ignored by the mode
+func (m WhitespaceMode) sameContent(from, to string) bool {
+	fromLines, toLines := m.compareLines(from), m.compareLines(to)
+	if len(fromLines) != len(toLines) {
+		return false
+	}
+	for i := range fromLines {
+		if fromLines[i] != toLines[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compareLines returns the normalized lines of content, without blank ones if they are ignored
+func (m WhitespaceMode) compareLines(content string) []string {
+	var lines []string
+	for _, line := range splitLines(content) {
+		if m&IgnoreBlankLines != 0 && isBlank(line) {
+			continue
+		}
+		lines = append(lines, m.normalizeLine(line))
+	}
+	return lines
+}
+
+// splitLines splits content into lines without their line feeds
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+////////////////////////////////////////////////////////////
+// changes
+////////////////////////////////////////////////////////////
+
+// filterWhitespaceChanges removes the modified files whose changes
+// are all ignored by mode
+func filterWhitespaceChanges(ctx context.Context, changes object.Changes, mode WhitespaceMode) (object.Changes, error) {
+	if mode == 0 {
+		return changes, nil
+	}
+
+	var result object.Changes
+	for _, c := range changes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		ignored, err := whitespaceOnlyChange(c, mode)
+		if err != nil {
+			return nil, err
+		}
+		if !ignored {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+// whitespaceOnlyChange returns true if c modifies a text file
+// only in whitespace ignored by mode
+func whitespaceOnlyChange(c *object.Change, mode WhitespaceMode) (bool, error) {
+	action, err := c.Action()
+	if err != nil || action != merkletrie.Modify || c.From.Name != c.To.Name {
+		return false, err
+	}
+
+	from, to, err := c.Files()
+	if err != nil {
+		return false, err
+	}
+
+	var contents []string
+	for _, file := range []*object.File{from, to} {
+		if binary, err := file.IsBinary(); err != nil || binary {
+			return false, err
+		}
+
+		content, err := file.Contents()
+		if err != nil {
+			return false, err
+		}
+		contents = append(contents, content)
+	}
+
+	return mode.sameContent(contents[0], contents[1]), nil
+}
+
+////////////////////////////////////////////////////////////
+// patch
+////////////////////////////////////////////////////////////
+
+// whitespaceFilePatch is a patch computed with whitespace differences ignored
+type whitespaceFilePatch struct {
+	patch diff.FilePatch
+	mode WhitespaceMode
+	chunks []diff.Chunk
+}
+
+// whitespaceChunk is a chunk of whitespaceFilePatch
+type whitespaceChunk struct {
+	content string
+	op diff.Operation
+}
+
+func (c *whitespaceChunk) Content() string {
+	return c.content
+}
+
+func (c *whitespaceChunk) Type() diff.Operation {
+	return c.op
+}
+
+// newWhitespaceFilePatch computes patch again with the whitespace differences of mode ignored.
+// Unchanged lines are the ones of the old file, as git shows them
+func newWhitespaceFilePatch(patch diff.FilePatch, mode WhitespaceMode) diff.FilePatch {
+	if mode == 0 || patch.IsBinary() {
+		return patch
+	}
+
+	// both files are made of the chunks
+	var from, to strings.Builder
+	for _, c := range patch.Chunks() {
+		if c.Type() != diff.Add {
+			from.WriteString(c.Content())
+		}
+		if c.Type() != diff.Delete {
+			to.WriteString(c.Content())
+		}
+	}
+	fromLines, toLines := splitLines(from.String()), splitLines(to.String())
+
+	normalize := func(lines []string) string {
+		var b strings.Builder
+		for _, line := range lines {
+			b.WriteString(mode.normalizeLine(line))
+			b.WriteByte('\n')
+		}
+		return b.String()
+	}
+
+	wp := &whitespaceFilePatch{patch: patch, mode: mode}
+	addChunk := func(lines []string, op diff.Operation) {
+		if len(lines) > 0 {
+			wp.chunks = append(wp.chunks, &whitespaceChunk{
+				content: strings.Join(lines, "\n") + "\n",
+				op: op,
+			})
+		}
+	}
+
+	fromIdx, toIdx := 0, 0
+	for _, d := range gitdiff.Do(normalize(fromLines), normalize(toLines)) {
+		count := strings.Count(d.Text, "\n")
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			addChunk(fromLines[fromIdx:fromIdx+count], diff.Equal)
+			fromIdx += count
+			toIdx += count
+		case diffmatchpatch.DiffDelete:
+			addChunk(fromLines[fromIdx:fromIdx+count], diff.Delete)
+			fromIdx += count
+		case diffmatchpatch.DiffInsert:
+			addChunk(toLines[toIdx:toIdx+count], diff.Add)
+			toIdx += count
+		}
+	}
+
+	return wp
+}
+
+func (wp *whitespaceFilePatch) IsBinary() bool {
+	return false
+}
+
+func (wp *whitespaceFilePatch) Files() (diff.File, diff.File) {
+	return wp.patch.Files()
+}
+
+func (wp *whitespaceFilePatch) Chunks() []diff.Chunk {
+	return wp.chunks
+}
+
+// patchWhitespaceMode returns the whitespace differences ignored by patch
+func patchWhitespaceMode(patch diff.FilePatch) WhitespaceMode {
+	if wp, ok := patch.(*whitespaceFilePatch); ok {
+		return wp.mode
+	}
+	return 0
+}
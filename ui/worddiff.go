@@ -0,0 +1,154 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxWordDiffTokens is the number of words in a line
+// above which changed words are not highlighted
+const MaxWordDiffTokens = 300
+
+// textRange is a range of bytes in a line
+type textRange struct {
+	start int
+	end int
+}
+
+// wordDiff compares the words of a deleted line and the added line replacing it,
+// and returns the ranges of the words which are not common to both.
+// Nothing is returned if the lines have no word in common
+func wordDiff(a, b string) (aChanges, bChanges []textRange) {
+	aWords, bWords := splitWords(a), splitWords(b)
+	if len(aWords) > MaxWordDiffTokens || len(bWords) > MaxWordDiffTokens {
+		return nil, nil
+	}
+
+	word := func(s string, r textRange) string {
+		return s[r.start:r.end]
+	}
+
+	// lcs[i][j] is the length of the longest common sequence of aWords[i:] and bWords[j:]
+	lcs := make([][]int, len(aWords)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bWords)+1)
+	}
+	for i := len(aWords) - 1; i >= 0; i-- {
+		for j := len(bWords) - 1; j >= 0; j-- {
+			if word(a, aWords[i]) == word(b, bWords[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	common := false
+	i, j := 0, 0
+	for i < len(aWords) && j < len(bWords) {
+		switch {
+		case word(a, aWords[i]) == word(b, bWords[j]):
+			if !isBlank(word(a, aWords[i])) {
+				common = true
+			}
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			aChanges = appendRange(aChanges, aWords[i])
+			i++
+		default:
+			bChanges = appendRange(bChanges, bWords[j])
+			j++
+		}
+	}
+	for ; i < len(aWords); i++ {
+		aChanges = appendRange(aChanges, aWords[i])
+	}
+	for ; j < len(bWords); j++ {
+		bChanges = appendRange(bChanges, bWords[j])
+	}
+
+	if !common {
+		// the line was rewritten, so highlighting words would only add noise
+		return nil, nil
+	}
+	return aChanges, bChanges
+}
+
+// appendRange adds r to ranges, merging it with the last one if they are adjacent
+func appendRange(ranges []textRange, r textRange) []textRange {
+	if n := len(ranges); n > 0 && ranges[n-1].end == r.start {
+		ranges[n-1].end = r.end
+		return ranges
+	}
+	return append(ranges, r)
+}
+
+// splitWords splits s into words, runs of spaces, and single other characters
+func splitWords(s string) []textRange {
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+
+	var words []textRange
+	for pos := 0; pos < len(s); {
+		r, size := utf8.DecodeRuneInString(s[pos:])
+		end := pos + size
+		if c := class(r); c != 0 {
+			for end < len(s) {
+				next, nextSize := utf8.DecodeRuneInString(s[end:])
+				if class(next) != c {
+					break
+				}
+				end += nextSize
+			}
+		}
+
+		words = append(words, textRange{start: pos, end: end})
+		pos = end
+	}
+
+	return words
+}
+
+// isBlank returns true if s only has spaces
+func isBlank(s string) bool {
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
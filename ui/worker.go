@@ -0,0 +1,221 @@
+/**
+ *  MIT License
+ *
+ *  Copyright (c) 2018-2018 Ji-Young Park(jiyoung.park.dev@gmail.com)
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *      The above copyright notice and this permission notice shall be included in all
+ *      copies or substantial portions of the Software.
+ *
+ *      THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *      IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *      FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *      AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *      LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *      OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *      SOFTWARE.
+ */
+
+package ui
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rivo/tview"
+)
+
+// JobKey identifies a kind of background job.
+// Submitting a job cancels the running job with the same key
+type JobKey string
+
+const (
+	// JobCommits loads the next page of commits
+	JobCommits JobKey = "commits"
+	// JobTree computes the tree diff of the selected commit
+	JobTree JobKey = "tree"
+	// JobStats computes file stats of the selected commit
+	JobStats JobKey = "stats"
+	// JobPatch computes the patch of the selected file
+	JobPatch JobKey = "patch"
+	// JobRefs lists references
+	JobRefs JobKey = "refs"
+	// JobRefPicker lists the references to choose from
+	JobRefPicker JobKey = "refPicker"
+	// JobLog opens the history of a revision
+	JobLog JobKey = "log"
+	// JobGoto resolves the revision of a commit to select
+	JobGoto JobKey = "goto"
+	// JobBookmarks reads the commits of bookmarks
+	JobBookmarks JobKey = "bookmarks"
+	// JobDecorations lists the refs pointing to commits
+	JobDecorations JobKey = "decorations"
+	// JobGrep searches the files of a commit
+	JobGrep JobKey = "grep"
+	// JobGrepFile reads a file found by grep
+	JobGrepFile JobKey = "grepFile"
+)
+
+// Job runs off the ui goroutine, one at a time in the order jobs are submitted,
+// since go-git repositories are not safe for concurrent use. It should stop early once ctx is done.
+// The function it returns, if not nil, is run on the ui goroutine
+// to apply the result unless the job has been cancelled in the meantime
+type Job func(ctx context.Context) func()
+
+// Worker runs jobs in the background and delivers their results
+// to the ui goroutine
+type Worker interface {
+	// Submit starts job, cancelling the running job with the same key
+	Submit(key JobKey, job Job)
+
+	// Cancel cancels the running job with the given key
+	Cancel(key JobKey)
+
+	// Run runs job in turn like Submit, but it is never cancelled,
+	// e.g. to release what cancelled jobs may still be using
+	Run(job Job)
+}
+
+type worker struct {
+	app *tview.Application
+
+	// the last job submitted for each key is kept until it is done,
+	// replaced or cancelled, so that its result can still be discarded
+	mutex sync.Mutex
+	jobs  map[JobKey]*runningJob
+
+	// queue holds the jobs waiting for their turn, first submitted first,
+	// and wakeup is signalled when one is added
+	queue  []queuedJob
+	wakeup chan struct{}
+}
+
+// runningJob is a job submitted and not done yet
+type runningJob struct {
+	cancel context.CancelFunc
+}
+
+// queuedJob is a job waiting for its turn
+type queuedJob struct {
+	ctx  context.Context
+	job  Job
+	done func()
+}
+
+////////////////////////////////////////////////////////////
+// worker functions
+////////////////////////////////////////////////////////////
+
+// NewWorker creates an instance of Worker
+func NewWorker(app *tview.Application) Worker {
+	w := &worker{
+		app:    app,
+		jobs:   make(map[JobKey]*runningJob),
+		wakeup: make(chan struct{}, 1),
+	}
+	go w.loop()
+
+	return w
+}
+
+func (w *worker) Submit(key JobKey, job Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &runningJob{cancel: cancel}
+
+	w.mutex.Lock()
+	if prev, exists := w.jobs[key]; exists {
+		prev.cancel()
+	}
+	w.jobs[key] = j
+	w.mutex.Unlock()
+
+	w.start(ctx, job, func() {
+		w.done(key, j)
+	})
+}
+
+func (w *worker) Run(job Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.start(ctx, job, cancel)
+}
+
+// start queues job to run in the background once the jobs queued before are over,
+// and calls done once its result has been applied or discarded
+func (w *worker) start(ctx context.Context, job Job, done func()) {
+	w.mutex.Lock()
+	w.queue = append(w.queue, queuedJob{ctx: ctx, job: job, done: done})
+	w.mutex.Unlock()
+
+	select {
+	case w.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// loop runs the queued jobs one at a time
+func (w *worker) loop() {
+	for {
+		w.mutex.Lock()
+		if len(w.queue) == 0 {
+			w.mutex.Unlock()
+			<-w.wakeup
+			continue
+		}
+		j := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mutex.Unlock()
+
+		w.run(j)
+	}
+}
+
+// run runs a queued job and delivers its result to the ui goroutine
+func (w *worker) run(j queuedJob) {
+	ctx := j.ctx
+	var update func()
+	// a job cancelled while waiting for its turn does not run
+	if ctx.Err() == nil {
+		update = j.job(ctx)
+	}
+
+	if update == nil || ctx.Err() != nil {
+		j.done()
+		return
+	}
+
+	w.app.QueueUpdateDraw(func() {
+		// jobs are submitted and cancelled on the ui goroutine,
+		// so this check cannot race with a newer submission
+		if ctx.Err() == nil {
+			update()
+		}
+		j.done()
+	})
+}
+
+// done forgets j unless it has been replaced, and releases its context
+func (w *worker) done(key JobKey, j *runningJob) {
+	w.mutex.Lock()
+	if w.jobs[key] == j {
+		delete(w.jobs, key)
+	}
+	w.mutex.Unlock()
+
+	j.cancel()
+}
+
+func (w *worker) Cancel(key JobKey) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if j, exists := w.jobs[key]; exists {
+		j.cancel()
+		delete(w.jobs, key)
+	}
+}